@@ -0,0 +1,196 @@
+// admin.go
+package main
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ChatPermissions are the rights restrictChatMember leaves a member.
+type ChatPermissions struct {
+	CanSendMessages       bool `json:"can_send_messages"`
+	CanSendMediaMessages  bool `json:"can_send_media_messages"`
+	CanSendPolls          bool `json:"can_send_polls"`
+	CanSendOtherMessages  bool `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews"`
+	CanChangeInfo         bool `json:"can_change_info"`
+	CanInviteUsers        bool `json:"can_invite_users"`
+	CanPinMessages        bool `json:"can_pin_messages"`
+}
+
+// AdminRights are the rights promoteChatMember grants, all false demotes.
+type AdminRights struct {
+	CanChangeInfo      bool `json:"can_change_info"`
+	CanPostMessages    bool `json:"can_post_messages"`
+	CanEditMessages    bool `json:"can_edit_messages"`
+	CanDeleteMessages  bool `json:"can_delete_messages"`
+	CanInviteUsers     bool `json:"can_invite_users"`
+	CanRestrictMembers bool `json:"can_restrict_members"`
+	CanPinMessages     bool `json:"can_pin_messages"`
+	CanPromoteMembers  bool `json:"can_promote_members"`
+}
+
+type ChatMember struct {
+	User   User   `json:"user"`
+	Status string `json:"status"`
+	// UntilDate is the unix time a ban or restriction ends, 0 is forever.
+	UntilDate   int  `json:"until_date"`
+	CanBeEdited bool `json:"can_be_edited"`
+	AdminRights
+	IsMember              bool `json:"is_member"`
+	CanSendMessages       bool `json:"can_send_messages"`
+	CanSendMediaMessages  bool `json:"can_send_media_messages"`
+	CanSendOtherMessages  bool `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews"`
+}
+
+type ChatReturn struct {
+	Result      Chat   `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+type ChatMemberReturn struct {
+	Result      ChatMember `json:"result"`
+	ErrorCode   int        `json:"error_code"`
+	Ok          bool       `json:"ok"`
+	Description string     `json:"description"`
+}
+
+type ChatMembersReturn struct {
+	Result      []ChatMember `json:"result"`
+	ErrorCode   int          `json:"error_code"`
+	Ok          bool         `json:"ok"`
+	Description string       `json:"description"`
+}
+
+type IntReturn struct {
+	Result      int    `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+type StringReturn struct {
+	Result      string `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+type PayloadChat struct {
+	ChatID int `json:"chat_id"`
+}
+
+type PayloadChatMember struct {
+	ChatID int `json:"chat_id"`
+	UserID int `json:"user_id"`
+}
+
+// kickChatMember bans userID until untilDate (unix time), 0 bans forever.
+func (obj *Action) kickChatMember(chatID, userID, untilDate int) error {
+	type Payload struct {
+		PayloadChatMember
+		UntilDate int `json:"until_date,omitempty"`
+	}
+	return obj.call("kickChatMember", Payload{PayloadChatMember{chatID, userID}, untilDate}, new(InlineReturn))
+}
+
+func (obj *Action) unbanChatMember(chatID, userID int) error {
+	return obj.call("unbanChatMember", PayloadChatMember{chatID, userID}, new(InlineReturn))
+}
+
+func (obj *Action) restrictChatMember(chatID, userID int, perms ChatPermissions, untilDate int) error {
+	type Payload struct {
+		PayloadChatMember
+		Permissions ChatPermissions `json:"permissions"`
+		UntilDate   int             `json:"until_date,omitempty"`
+	}
+	return obj.call("restrictChatMember", Payload{PayloadChatMember{chatID, userID}, perms, untilDate}, new(InlineReturn))
+}
+
+func (obj *Action) promoteChatMember(chatID, userID int, rights AdminRights) error {
+	type Payload struct {
+		PayloadChatMember
+		AdminRights
+	}
+	return obj.call("promoteChatMember", Payload{PayloadChatMember{chatID, userID}, rights}, new(InlineReturn))
+}
+
+func (obj *Action) exportChatInviteLink(chatID int) (string, error) {
+	ret := new(StringReturn)
+	err := obj.call("exportChatInviteLink", PayloadChat{chatID}, ret)
+	return ret.Result, err
+}
+
+func (obj *Action) setChatTitle(chatID int, title string) error {
+	type Payload struct {
+		ChatID int    `json:"chat_id"`
+		Title  string `json:"title"`
+	}
+	return obj.call("setChatTitle", Payload{chatID, title}, new(InlineReturn))
+}
+
+func (obj *Action) setChatDescription(chatID int, description string) error {
+	type Payload struct {
+		ChatID      int    `json:"chat_id"`
+		Description string `json:"description"`
+	}
+	return obj.call("setChatDescription", Payload{chatID, description}, new(InlineReturn))
+}
+
+// setChatPhoto needs a file to upload, Telegram does not accept a file_id
+// or URL here.
+func (obj *Action) setChatPhoto(chatID int, photo InputFile) error {
+	if !photo.upload() {
+		return errors.New("setChatPhoto: photo must be uploaded")
+	}
+	params := map[string]string{"chat_id": strconv.Itoa(chatID)}
+	return obj.callUpload("setChatPhoto", params, map[string]InputFile{"photo": photo}, new(InlineReturn))
+}
+
+func (obj *Action) pinChatMessage(chatID, messageID int, disableNotification bool) error {
+	type Payload struct {
+		ChatID              int  `json:"chat_id"`
+		MessageID           int  `json:"message_id"`
+		DisableNotification bool `json:"disable_notification,omitempty"`
+	}
+	return obj.call("pinChatMessage", Payload{chatID, messageID, disableNotification}, new(InlineReturn))
+}
+
+func (obj *Action) unpinChatMessage(chatID int) error {
+	return obj.call("unpinChatMessage", PayloadChat{chatID}, new(InlineReturn))
+}
+
+func (obj *Action) leaveChat(chatID int) error {
+	return obj.call("leaveChat", PayloadChat{chatID}, new(InlineReturn))
+}
+
+func (obj *Action) getChat(chatID int) (*Chat, error) {
+	ret := new(ChatReturn)
+	if err := obj.call("getChat", PayloadChat{chatID}, ret); err != nil {
+		return nil, err
+	}
+	return &ret.Result, nil
+}
+
+func (obj *Action) getChatAdministrators(chatID int) ([]ChatMember, error) {
+	ret := new(ChatMembersReturn)
+	err := obj.call("getChatAdministrators", PayloadChat{chatID}, ret)
+	return ret.Result, err
+}
+
+func (obj *Action) getChatMembersCount(chatID int) (int, error) {
+	ret := new(IntReturn)
+	err := obj.call("getChatMembersCount", PayloadChat{chatID}, ret)
+	return ret.Result, err
+}
+
+func (obj *Action) getChatMember(chatID, userID int) (*ChatMember, error) {
+	ret := new(ChatMemberReturn)
+	if err := obj.call("getChatMember", PayloadChatMember{chatID, userID}, ret); err != nil {
+		return nil, err
+	}
+	return &ret.Result, nil
+}
@@ -0,0 +1,233 @@
+// albums.go
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const (
+	minAlbumSize = 2
+	maxAlbumSize = 10
+	albumWindow  = time.Second
+
+	albumsBucket       = "Albums"
+	albumCollectorName = "default"
+)
+
+type MessagesReturn struct {
+	Result      []Message `json:"result"`
+	ErrorCode   int       `json:"error_code"`
+	Ok          bool      `json:"ok"`
+	Description string    `json:"description"`
+}
+
+// AlbumItem is one photo or video of an album.
+type AlbumItem struct {
+	File      InputFile
+	Video     bool
+	Caption   string
+	ParseMode string
+}
+
+// sendMediaGroup sends 2 to 10 photos and videos as one album. Uploaded
+// files are attached to the request by name.
+func (obj *Action) sendMediaGroup(chatID int, items []AlbumItem, disableNotification bool, replyToMessageID int) ([]Message, error) {
+	if len(items) < minAlbumSize || len(items) > maxAlbumSize {
+		return nil, errors.New("sendMediaGroup: an album takes 2 to 10 items")
+	}
+	files := make(map[string]InputFile)
+	media := make([]InputMedia, 0, len(items))
+	for i, item := range items {
+		ref := item.File.value()
+		if item.File.upload() {
+			name := "file" + strconv.Itoa(i)
+			files[name] = item.File
+			ref = "attach://" + name
+		}
+		if item.Video {
+			media = append(media, InputMediaVideo{Media: ref, Caption: item.Caption, ParseMode: item.ParseMode})
+		} else {
+			media = append(media, InputMediaPhoto{Media: ref, Caption: item.Caption, ParseMode: item.ParseMode})
+		}
+	}
+	mediaJson, err := json.Marshal(media)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{"chat_id": strconv.Itoa(chatID), "media": string(mediaJson)}
+	if disableNotification {
+		params["disable_notification"] = "true"
+	}
+	if replyToMessageID != 0 {
+		params["reply_to_message_id"] = strconv.Itoa(replyToMessageID)
+	}
+	ret := new(MessagesReturn)
+	if err := obj.callUpload("sendMediaGroup", params, files, ret); err != nil {
+		return nil, err
+	}
+	return ret.Result, nil
+}
+
+// AlbumHandler gets all parts of an album ordered by message ID.
+type AlbumHandler func(obj *Action, album []*Message)
+
+// AlbumCollector buffers the messages of an album, which Telegram delivers
+// one update per part, until no part arrived for Window. The parts are kept
+// in bolt, so the offset is committed as usual and a restart loses nothing,
+// call Resume at startup to deliver albums a previous run left buffered.
+// OnAlbum runs on a timer goroutine under obj.mu, so it never runs next to
+// other handlers. Name tells collectors apart and must be set when more
+// than one is used.
+type AlbumCollector struct {
+	Name    string
+	Window  time.Duration
+	OnAlbum AlbumHandler
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer
+}
+
+// Collect wraps next, messages without media_group_id are passed to it
+// directly.
+func (c *AlbumCollector) Collect(next MessageHandler) MessageHandler {
+	return func(obj *Action, msg *Message) {
+		if msg.MediaGroupID == "" || c.OnAlbum == nil {
+			if next != nil {
+				next(obj, msg)
+			}
+			return
+		}
+		if err := c.store(obj, msg); err != nil {
+			Dbg(err)
+			return
+		}
+		c.schedule(obj, msg.MediaGroupID)
+	}
+}
+
+// Resume schedules every album stored by an earlier run.
+func (c *AlbumCollector) Resume(obj *Action) error {
+	var ids []string
+	err := obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := c.bucket(tx)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	for _, id := range ids {
+		c.schedule(obj, id)
+	}
+	return err
+}
+
+func (c *AlbumCollector) bucket(tx *bolt.Tx) *bolt.Bucket {
+	root := tx.Bucket([]byte(albumsBucket))
+	if root == nil {
+		return nil
+	}
+	return root.Bucket(c.name())
+}
+
+func (c *AlbumCollector) name() []byte {
+	if c.Name == "" {
+		return []byte(albumCollectorName)
+	}
+	return []byte(c.Name)
+}
+
+func (c *AlbumCollector) store(obj *Action, msg *Message) error {
+	v, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(albumsBucket))
+		if err != nil {
+			return err
+		}
+		collector, err := root.CreateBucketIfNotExists(c.name())
+		if err != nil {
+			return err
+		}
+		b, err := collector.CreateBucketIfNotExists([]byte(msg.MediaGroupID))
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(msg.MessageID))
+		return b.Put(key, v)
+	})
+}
+
+func (c *AlbumCollector) schedule(obj *Action, id string) {
+	window := c.Window
+	if window == 0 {
+		window = albumWindow
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string]*time.Timer)
+	}
+	if timer, ok := c.pending[id]; ok {
+		timer.Reset(window)
+		return
+	}
+	c.pending[id] = time.AfterFunc(window, func() { c.flush(obj, id) })
+}
+
+// flush hands the album to OnAlbum and drops it from bolt afterwards, like
+// an update whose offset is committed after its handler ran.
+func (c *AlbumCollector) flush(obj *Action, id string) {
+	obj.mu.Lock()
+	defer obj.mu.Unlock()
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+
+	var album []*Message
+	err := obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := c.bucket(tx)
+		if b == nil || b.Bucket([]byte(id)) == nil {
+			return nil
+		}
+		return b.Bucket([]byte(id)).ForEach(func(k, v []byte) error {
+			msg := new(Message)
+			if err := json.Unmarshal(v, msg); err != nil {
+				return err
+			}
+			album = append(album, msg)
+			return nil
+		})
+	})
+	if err != nil {
+		Dbg(err)
+		return
+	}
+	if len(album) == 0 {
+		return
+	}
+	sort.Slice(album, func(i, j int) bool {
+		return album[i].MessageID < album[j].MessageID
+	})
+	c.OnAlbum(obj, album)
+	Dbg(obj.Bolt.Update(func(tx *bolt.Tx) error {
+		if b := c.bucket(tx); b != nil {
+			return b.DeleteBucket([]byte(id))
+		}
+		return nil
+	}))
+}
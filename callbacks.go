@@ -0,0 +1,154 @@
+// callbacks.go
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/ulvham/helper"
+)
+
+const (
+	callbackSep     = ":"
+	callbackSigSep  = "."
+	callbackSigSize = 8
+)
+
+var ErrCallbackForged = errors.New("callback data signature mismatch")
+
+// CallbackAnswer is what a handler wants shown for the pressed button.
+type CallbackAnswer struct {
+	Text      string
+	ShowAlert bool
+	Url       string
+	CacheTime int
+}
+
+// CallbackHandler gets the parameters captured by the route's pattern. A nil
+// answer only stops the spinner on the button.
+type CallbackHandler func(obj *Action, query *CallbackQuery, params map[string]string) *CallbackAnswer
+
+type callbackRoute struct {
+	segments []string
+	prefix   bool
+	handler  CallbackHandler
+}
+
+// CallbackRouter matches callback data against registered patterns. With
+// Secret set only data produced by EncodeCallback with the same secret is
+// routed, anything else is answered without calling a handler. Game
+// callbacks are answered from the urls registered with HandleGame, signed
+// with GameSecret.
+type CallbackRouter struct {
+	routes     []callbackRoute
+	games      map[string]string
+	Secret     []byte
+	GameSecret []byte
+	Fallback   CallbackHandler
+}
+
+// Handle registers h for pattern. Segments are split on ':', a segment
+// written as <name> captures the value under name, a trailing '*' matches
+// any rest, which is captured under "*". For example "vote:<poll>:<option>"
+// or "menu:*".
+func (r *CallbackRouter) Handle(pattern string, h CallbackHandler) {
+	route := callbackRoute{handler: h}
+	if strings.HasSuffix(pattern, "*") {
+		route.prefix = true
+		pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "*"), callbackSep)
+	}
+	if pattern != "" {
+		route.segments = strings.Split(pattern, callbackSep)
+	}
+	r.routes = append(r.routes, route)
+}
+
+func (route *callbackRoute) match(data string) (map[string]string, bool) {
+	parts := strings.Split(data, callbackSep)
+	if len(parts) < len(route.segments) || (!route.prefix && len(parts) != len(route.segments)) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, seg := range route.segments {
+		if strings.HasPrefix(seg, "<") && strings.HasSuffix(seg, ">") {
+			params[seg[1:len(seg)-1]] = parts[i]
+		} else if seg != parts[i] {
+			return nil, false
+		}
+	}
+	if route.prefix {
+		params["*"] = strings.Join(parts[len(route.segments):], callbackSep)
+	}
+	return params, true
+}
+
+// route is a CallbackQueryHandler, register it as obj.Handlers.OnCallbackQuery.
+func (r *CallbackRouter) route(obj *Action, query *CallbackQuery) {
+	if query.GameShortName != "" {
+		Dbg(obj.answerCallbackQuery(query, r.gameAnswer(query)))
+		return
+	}
+	data := query.Data
+	if len(r.Secret) > 0 {
+		var err error
+		data, err = DecodeCallback(r.Secret, data)
+		if err != nil {
+			Dbg(err)
+			Dbg(obj.answerCallbackQuery(query, nil))
+			return
+		}
+	}
+	var answer *CallbackAnswer
+	matched := false
+	for i := range r.routes {
+		if params, ok := r.routes[i].match(data); ok {
+			answer = r.routes[i].handler(obj, query, params)
+			matched = true
+			break
+		}
+	}
+	if !matched && r.Fallback != nil {
+		answer = r.Fallback(obj, query, map[string]string{"*": data})
+	}
+	Dbg(obj.answerCallbackQuery(query, answer))
+}
+
+func callbackSign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:callbackSigSize])
+}
+
+// EncodeCallback joins fields with ':' and appends a truncated HMAC-SHA256 of
+// them, the result must fit the 64 bytes Telegram allows for callback_data.
+func EncodeCallback(secret []byte, fields ...string) (string, error) {
+	for _, f := range fields {
+		if strings.Contains(f, callbackSep) || strings.Contains(f, callbackSigSep) {
+			return "", fmt.Errorf("callback field %q contains a separator", f)
+		}
+	}
+	payload := strings.Join(fields, callbackSep)
+	data := payload + callbackSigSep + callbackSign(secret, payload)
+	if len(data) > maxCallbackData {
+		return "", fmt.Errorf("signed callback data is %d bytes, limit is %d", len(data), maxCallbackData)
+	}
+	return data, nil
+}
+
+// DecodeCallback checks the signature written by EncodeCallback and returns
+// the payload without it.
+func DecodeCallback(secret []byte, data string) (string, error) {
+	i := strings.LastIndex(data, callbackSigSep)
+	if i < 0 {
+		return "", ErrCallbackForged
+	}
+	payload, sig := data[:i], data[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(callbackSign(secret, payload))) {
+		return "", ErrCallbackForged
+	}
+	return payload, nil
+}
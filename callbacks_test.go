@@ -0,0 +1,75 @@
+// callbacks_test.go
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCallbackEncoding(t *testing.T) {
+	secret := []byte("secret")
+	data, err := EncodeCallback(secret, "vote", "42", "yes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > maxCallbackData {
+		t.Fatalf("%q is longer than %d bytes", data, maxCallbackData)
+	}
+	tests := []struct {
+		name    string
+		secret  []byte
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"round trip", secret, data, "vote:42:yes", false},
+		{"other secret", []byte("other"), data, "", true},
+		{"payload changed", secret, strings.Replace(data, "yes", "no!", 1), "", true},
+		{"signature changed", secret, data[:len(data)-1] + "A", "", true},
+		{"unsigned", secret, "vote:42:yes", "", true},
+	}
+	for _, tt := range tests {
+		got, err := DecodeCallback(tt.secret, tt.data)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: DecodeCallback(%q) = %q, %v", tt.name, tt.data, got, err)
+		}
+	}
+
+	if _, err := EncodeCallback(secret, "a:b"); err == nil {
+		t.Error("a field with ':' was encoded")
+	}
+	if _, err := EncodeCallback(secret, "a.b"); err == nil {
+		t.Error("a field with '.' was encoded")
+	}
+	if _, err := EncodeCallback(secret, strings.Repeat("x", maxCallbackData)); err == nil {
+		t.Error("data over the size limit was encoded")
+	}
+}
+
+func TestCallbackRouteMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		data    string
+		want    map[string]string
+		ok      bool
+	}{
+		{"vote:<poll>:<option>", "vote:7:yes", map[string]string{"poll": "7", "option": "yes"}, true},
+		{"vote:<poll>:<option>", "vote:7", nil, false},
+		{"vote:<poll>:<option>", "vote:7:yes:extra", nil, false},
+		{"vote:<poll>", "poll:7", nil, false},
+		{"menu:*", "menu:a:b", map[string]string{"*": "a:b"}, true},
+		{"menu:*", "menu", map[string]string{"*": ""}, true},
+		{"menu:*", "other:a", nil, false},
+		{"*", "anything:at:all", map[string]string{"*": "anything:at:all"}, true},
+		{"ping", "ping", map[string]string{}, true},
+	}
+	for _, tt := range tests {
+		r := &CallbackRouter{}
+		r.Handle(tt.pattern, nil)
+		got, ok := r.routes[0].match(tt.data)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("%q on %q = %v, %v, want %v, %v", tt.pattern, tt.data, got, ok, tt.want, tt.ok)
+		}
+	}
+}
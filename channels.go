@@ -0,0 +1,60 @@
+// channels.go
+package main
+
+// ForwardInfo describes where a forwarded message came from.
+type ForwardInfo struct {
+	From          *User
+	FromChat      *Chat
+	FromMessageID int
+	Signature     string
+	Date          int
+}
+
+// Forward returns the origin of a forwarded message, nil for original ones.
+func (msg *Message) Forward() *ForwardInfo {
+	if msg.ForwardDate == 0 {
+		return nil
+	}
+	fwd := &ForwardInfo{
+		FromMessageID: msg.ForwardFromMessageID,
+		Signature:     msg.ForwardSignature,
+		Date:          msg.ForwardDate,
+	}
+	if msg.ForwardFrom.ID != 0 {
+		fwd.From = &msg.ForwardFrom
+	}
+	if msg.ForwardFromChat.ID != 0 {
+		fwd.FromChat = &msg.ForwardFromChat
+	}
+	return fwd
+}
+
+// ChannelPost is a channel message with the metadata channels add to it.
+type ChannelPost struct {
+	*Message
+	Channel *Chat
+	// Signature is the author's name when the channel signs its posts.
+	Signature string
+	Forward   *ForwardInfo
+	Edited    bool
+}
+
+type ChannelPostHandler func(obj *Action, post *ChannelPost)
+
+// ChannelPosts adapts h to the two message handlers of the dispatcher:
+//
+//	obj.Handlers.OnChannelPost, obj.Handlers.OnEditedChannelPost = ChannelPosts(h)
+func ChannelPosts(h ChannelPostHandler) (post, edited MessageHandler) {
+	wrap := func(isEdit bool) MessageHandler {
+		return func(obj *Action, msg *Message) {
+			h(obj, &ChannelPost{
+				Message:   msg,
+				Channel:   &msg.Chat,
+				Signature: msg.AuthorSignature,
+				Forward:   msg.Forward(),
+				Edited:    isEdit,
+			})
+		}
+	}
+	return wrap(false), wrap(true)
+}
@@ -0,0 +1,132 @@
+// chosen.go
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const (
+	chosenBucket    = "Chosen"
+	chosenByResult  = "ByResult"
+	chosenByQuery   = "ByQuery"
+	chosenLogBucket = "Log"
+	// chosenEmptyQuery counts choices from an empty query, bolt refuses
+	// empty keys
+	chosenEmptyQuery = "\x00"
+)
+
+// TrackChosen records every chosen inline result in bolt before handing it
+// to h, which may be nil. Enable feedback collection with /setinlinefeedback
+// in @BotFather, otherwise Telegram sends no chosen_inline_result at all.
+func TrackChosen(h ChosenInlineResultHandler) ChosenInlineResultHandler {
+	return func(obj *Action, result *ChosenInlineResult) {
+		Dbg(obj.recordChosen(result))
+		if h != nil {
+			h(obj, result)
+		}
+	}
+}
+
+func incCounter(b *bolt.Bucket, key string) error {
+	n, _ := strconv.Atoi(string(b.Get([]byte(key))))
+	return b.Put([]byte(key), []byte(strconv.Itoa(n+1)))
+}
+
+func (obj *Action) recordChosen(result *ChosenInlineResult) error {
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(chosenBucket))
+		if err != nil {
+			return err
+		}
+		for _, name := range []string{chosenByResult, chosenByQuery, chosenLogBucket} {
+			if _, err := root.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		if err := incCounter(root.Bucket([]byte(chosenByResult)), result.ResultID); err != nil {
+			return err
+		}
+		query := result.Query
+		if query == "" {
+			query = chosenEmptyQuery
+		}
+		if err := incCounter(root.Bucket([]byte(chosenByQuery)), query); err != nil {
+			return err
+		}
+
+		log := root.Bucket([]byte(chosenLogBucket))
+		seq, err := log.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		v, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return log.Put(key, v)
+	})
+}
+
+func readCounters(root *bolt.Bucket, name string) map[string]int {
+	counts := make(map[string]int)
+	if b := root.Bucket([]byte(name)); b != nil {
+		b.ForEach(func(k, v []byte) error {
+			counts[string(k)], _ = strconv.Atoi(string(v))
+			return nil
+		})
+	}
+	return counts
+}
+
+// chosenCounts returns how often each result_id and each query was picked,
+// the empty query included.
+func (obj *Action) chosenCounts() (byResult, byQuery map[string]int) {
+	byResult = make(map[string]int)
+	byQuery = make(map[string]int)
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte(chosenBucket))
+		if root == nil {
+			return nil
+		}
+		byResult = readCounters(root, chosenByResult)
+		byQuery = readCounters(root, chosenByQuery)
+		if n, ok := byQuery[chosenEmptyQuery]; ok {
+			delete(byQuery, chosenEmptyQuery)
+			byQuery[""] = n
+		}
+		return nil
+	})
+	return byResult, byQuery
+}
+
+// chosenLog returns the recorded choices of resultID in order, all of them
+// when resultID is empty. Their InlineMessageID can be passed to the
+// inline_message_id variants of the edit methods; Telegram fills it only for
+// results that carry an inline keyboard.
+func (obj *Action) chosenLog(resultID string) []ChosenInlineResult {
+	var ret []ChosenInlineResult
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte(chosenBucket))
+		if root == nil || root.Bucket([]byte(chosenLogBucket)) == nil {
+			return nil
+		}
+		return root.Bucket([]byte(chosenLogBucket)).ForEach(func(k, v []byte) error {
+			result := ChosenInlineResult{}
+			if err := json.Unmarshal(v, &result); err != nil {
+				return err
+			}
+			if resultID == "" || result.ResultID == resultID {
+				ret = append(ret, result)
+			}
+			return nil
+		})
+	})
+	return ret
+}
@@ -0,0 +1,35 @@
+// chosen_test.go
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordChosen(t *testing.T) {
+	obj := newTestAction(t)
+	for _, result := range []*ChosenInlineResult{
+		{ResultID: "r1"},
+		{ResultID: "r1", Query: "cats"},
+		{ResultID: "r2", Query: "cats"},
+		{ResultID: "r2"},
+	} {
+		if err := obj.recordChosen(result); err != nil {
+			t.Fatalf("recordChosen(%+v): %v", result, err)
+		}
+	}
+
+	byResult, byQuery := obj.chosenCounts()
+	if want := map[string]int{"r1": 2, "r2": 2}; !reflect.DeepEqual(byResult, want) {
+		t.Errorf("by result = %v, want %v", byResult, want)
+	}
+	if want := map[string]int{"": 2, "cats": 2}; !reflect.DeepEqual(byQuery, want) {
+		t.Errorf("by query = %v, want %v", byQuery, want)
+	}
+	if got := obj.chosenLog("r1"); len(got) != 2 || got[0].Query != "" || got[1].Query != "cats" {
+		t.Errorf("log of r1 = %+v", got)
+	}
+	if got := obj.chosenLog(""); len(got) != 4 {
+		t.Errorf("log has %d entries, want 4", len(got))
+	}
+}
@@ -0,0 +1,146 @@
+// client.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+
+	. "github.com/ulvham/helper"
+	"golang.org/x/net/proxy"
+)
+
+// ApiError is the envelope every Bot API answer carries next to its result.
+type ApiError struct {
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.ErrorCode, e.Description)
+}
+
+// httpClient returns the client of the Action, built on first use so that
+// it honours the SOCKS5 proxy settings. It is shared by all calls, which
+// keeps the keep-alive connections of its transport in use.
+func (obj *Action) httpClient() *http.Client {
+	obj.clientOnce.Do(func() {
+		httpTransport := &http.Transport{}
+		if obj.ProxyUsage {
+			dialer, err := proxy.SOCKS5("tcp", obj.ProxyUrl, nil, proxy.Direct)
+			Dbg(err)
+			httpTransport.Dial = dialer.Dial
+		}
+		obj.client = &http.Client{Transport: httpTransport}
+	})
+	return obj.client
+}
+
+// call posts payload as JSON to the given Bot API method and decodes the answer into ret.
+// A non-ok answer is returned as *ApiError.
+func (obj *Action) call(method string, payload interface{}, ret interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", telegramUrl+api+"/"+method, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := obj.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	bodyret, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return decodeAnswer(bodyret, ret)
+}
+
+// callUpload posts params and files as multipart/form-data. Files that are
+// a URL or file_id go in as plain fields. The body is streamed, uploads are
+// never held in memory whole.
+func (obj *Action) callUpload(method string, params map[string]string, files map[string]InputFile, ret interface{}) error {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeForm(form, params, files))
+	}()
+
+	req, err := http.NewRequest("POST", telegramUrl+api+"/"+method, pr)
+	if err != nil {
+		pr.Close()
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := obj.httpClient().Do(req)
+	if err != nil {
+		pr.Close()
+		return err
+	}
+	defer resp.Body.Close()
+
+	bodyret, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return decodeAnswer(bodyret, ret)
+}
+
+func writeForm(form *multipart.Writer, params map[string]string, files map[string]InputFile) error {
+	for k, v := range params {
+		if err := form.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	for field, file := range files {
+		if !file.upload() {
+			if err := form.WriteField(field, file.value()); err != nil {
+				return err
+			}
+			continue
+		}
+		r, name, err := file.open()
+		if err != nil {
+			return err
+		}
+		part, err := form.CreateFormFile(field, name)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if c, ok := r.(io.Closer); ok && file.Path != "" {
+			c.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+func decodeAnswer(bodyret []byte, ret interface{}) error {
+	apiErr := new(ApiError)
+	if err := json.Unmarshal(bodyret, apiErr); err != nil {
+		return err
+	}
+	if !apiErr.Ok {
+		return apiErr
+	}
+	if ret == nil {
+		return nil
+	}
+	return json.Unmarshal(bodyret, ret)
+}
@@ -0,0 +1,137 @@
+// commands.go
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	. "github.com/ulvham/helper"
+)
+
+const meAttempts = 5
+
+type UserReturn struct {
+	Result      User   `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Command is one bot_command entity of a message with the text following it.
+type Command struct {
+	Name    string
+	Mention string
+	Args    []string
+	RawArgs string
+}
+
+type CommandHandler func(obj *Action, msg *Message, cmd *Command)
+
+// CommandRouter calls the handler registered for every command addressed to
+// the bot. Unknown commands go to Fallback, messages without commands to Text.
+type CommandRouter struct {
+	handlers map[string]CommandHandler
+	Fallback CommandHandler
+	Text     MessageHandler
+}
+
+// getMe fetches the bot's own user, its username is needed to tell
+// /command@ThisBot from /command@OtherBot.
+func (obj *Action) getMe() error {
+	ret := new(UserReturn)
+	if err := obj.call("getMe", struct{}{}, ret); err != nil {
+		return err
+	}
+	obj.Me = ret.Result
+	return nil
+}
+
+// awaitMe calls getMe until it succeeds, network errors are retried
+// meAttempts times, an answer from Telegram such as a bad token is final.
+// Routing commands needs the username, so it must not stay empty.
+func (obj *Action) awaitMe() error {
+	var err error
+	for i := 0; i < meAttempts; i++ {
+		if i > 0 {
+			time.Sleep(pollRetryGap)
+		}
+		if err = obj.getMe(); err == nil {
+			break
+		}
+		if _, ok := err.(*ApiError); ok {
+			return err
+		}
+		Dbg(err)
+	}
+	if err != nil {
+		return err
+	}
+	if obj.Me.Username == "" {
+		return errors.New("getMe returned no username")
+	}
+	return nil
+}
+
+// Handle registers h for /name, name is given without the leading slash.
+func (r *CommandRouter) Handle(name string, h CommandHandler) {
+	if r.handlers == nil {
+		r.handlers = make(map[string]CommandHandler)
+	}
+	r.handlers[strings.ToLower(name)] = h
+}
+
+// route is a MessageHandler, register it as obj.Handlers.OnMessage.
+func (r *CommandRouter) route(obj *Action, msg *Message) {
+	cmds := parseCommands(msg)
+	if len(cmds) == 0 {
+		if r.Text != nil {
+			r.Text(obj, msg)
+		}
+		return
+	}
+	for _, cmd := range cmds {
+		if cmd.Mention != "" && !strings.EqualFold(cmd.Mention, obj.Me.Username) {
+			continue
+		}
+		if h, ok := r.handlers[strings.ToLower(cmd.Name)]; ok {
+			h(obj, msg, cmd)
+		} else if r.Fallback != nil {
+			r.Fallback(obj, msg, cmd)
+		}
+	}
+}
+
+// parseCommands reads the bot_command entities of msg. The arguments of a
+// command are the text up to the next command.
+func parseCommands(msg *Message) []*Command {
+	text := utf16.Encode([]rune(msg.Text))
+	var cmds []*Command
+	for i, ent := range msg.Entities {
+		if ent.Type != "bot_command" || ent.Offset+ent.Length > len(text) {
+			continue
+		}
+		end := len(text)
+		for _, next := range msg.Entities[i+1:] {
+			if next.Type == "bot_command" {
+				end = next.Offset
+				break
+			}
+		}
+		cmd := &Command{}
+		name := utf16Slice(text, ent.Offset, ent.Length)
+		name = strings.TrimPrefix(name, "/")
+		if at := strings.Index(name, "@"); at >= 0 {
+			cmd.Mention = name[at+1:]
+			name = name[:at]
+		}
+		cmd.Name = name
+		if end > ent.Offset+ent.Length {
+			cmd.RawArgs = strings.TrimSpace(utf16Slice(text, ent.Offset+ent.Length, end-ent.Offset-ent.Length))
+		}
+		cmd.Args = strings.Fields(cmd.RawArgs)
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
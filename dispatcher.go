@@ -0,0 +1,101 @@
+// dispatcher.go
+package main
+
+type MessageHandler func(obj *Action, msg *Message)
+type InlineQueryHandler func(obj *Action, query *InlineQuery)
+type ChosenInlineResultHandler func(obj *Action, result *ChosenInlineResult)
+type CallbackQueryHandler func(obj *Action, query *CallbackQuery)
+type ShippingQueryHandler func(obj *Action, query *ShippingQuery)
+type PreCheckoutQueryHandler func(obj *Action, query *PreCheckoutQuery)
+
+// Dispatcher holds one handler per update type. Updates whose handler is nil
+// are dropped.
+type Dispatcher struct {
+	OnMessage            MessageHandler
+	OnEditedMessage      MessageHandler
+	OnChannelPost        MessageHandler
+	OnEditedChannelPost  MessageHandler
+	OnInlineQuery        InlineQueryHandler
+	OnChosenInlineResult ChosenInlineResultHandler
+	OnCallbackQuery      CallbackQueryHandler
+	OnShippingQuery      ShippingQueryHandler
+	OnPreCheckoutQuery   PreCheckoutQueryHandler
+}
+
+// allowedUpdates lists the update types that have a handler, so Telegram
+// does not deliver what would be dropped anyway.
+func (d *Dispatcher) allowedUpdates() []string {
+	var allowed []string
+	add := func(set bool, name string) {
+		if set {
+			allowed = append(allowed, name)
+		}
+	}
+	add(d.OnMessage != nil, "message")
+	add(d.OnEditedMessage != nil, "edited_message")
+	add(d.OnChannelPost != nil, "channel_post")
+	add(d.OnEditedChannelPost != nil, "edited_channel_post")
+	add(d.OnInlineQuery != nil, "inline_query")
+	add(d.OnChosenInlineResult != nil, "chosen_inline_result")
+	add(d.OnCallbackQuery != nil, "callback_query")
+	add(d.OnShippingQuery != nil, "shipping_query")
+	add(d.OnPreCheckoutQuery != nil, "pre_checkout_query")
+	return allowed
+}
+
+// allowedUpdates adds messages to the dispatcher's list when only chat
+// events are handled.
+func (obj *Action) allowedUpdates() []string {
+	allowed := obj.Handlers.allowedUpdates()
+	if obj.Handlers.OnMessage == nil && obj.Events.any() {
+		allowed = append([]string{"message"}, allowed...)
+	}
+	return allowed
+}
+
+// dispatch looks at which field of upd is set and calls only the handler
+// registered for that type. Chat events in messages go to obj.Events.
+func (obj *Action) dispatch(upd *Update) {
+	h := &obj.Handlers
+	switch {
+	case upd.Message != nil:
+		if obj.Events.handle(obj, upd.Message) {
+			return
+		}
+		if h.OnMessage != nil {
+			h.OnMessage(obj, upd.Message)
+		}
+	case upd.EditedMessage != nil:
+		if h.OnEditedMessage != nil {
+			h.OnEditedMessage(obj, upd.EditedMessage)
+		}
+	case upd.ChannelPost != nil:
+		if h.OnChannelPost != nil {
+			h.OnChannelPost(obj, upd.ChannelPost)
+		}
+	case upd.EditedChannelPost != nil:
+		if h.OnEditedChannelPost != nil {
+			h.OnEditedChannelPost(obj, upd.EditedChannelPost)
+		}
+	case upd.InlineQuery != nil:
+		if h.OnInlineQuery != nil {
+			h.OnInlineQuery(obj, upd.InlineQuery)
+		}
+	case upd.ChosenInlineResult != nil:
+		if h.OnChosenInlineResult != nil {
+			h.OnChosenInlineResult(obj, upd.ChosenInlineResult)
+		}
+	case upd.CallbackQuery != nil:
+		if h.OnCallbackQuery != nil {
+			h.OnCallbackQuery(obj, upd.CallbackQuery)
+		}
+	case upd.ShippingQuery != nil:
+		if h.OnShippingQuery != nil {
+			h.OnShippingQuery(obj, upd.ShippingQuery)
+		}
+	case upd.PreCheckoutQuery != nil:
+		if h.OnPreCheckoutQuery != nil {
+			h.OnPreCheckoutQuery(obj, upd.PreCheckoutQuery)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// download.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	filesBucket = "Files"
+	// maxDownloadSize is what the Bot API lets bots download.
+	maxDownloadSize = 20 << 20
+)
+
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int    `json:"file_size"`
+	FilePath string `json:"file_path"`
+}
+
+type FileReturn struct {
+	Result      File   `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// getFile prepares a file for download, the FilePath it returns is valid
+// for at least an hour.
+func (obj *Action) getFile(fileID string) (*File, error) {
+	type Payload struct {
+		FileID string `json:"file_id"`
+	}
+	ret := new(FileReturn)
+	if err := obj.call("getFile", Payload{fileID}, ret); err != nil {
+		return nil, err
+	}
+	return &ret.Result, nil
+}
+
+// Downloader saves files into Dir, each file ID once. Progress, when set,
+// is called as bytes arrive; total is 0 when Telegram did not report a size.
+type Downloader struct {
+	Dir      string
+	MaxSize  int64
+	Progress func(fileID string, done, total int64)
+}
+
+type progressReader struct {
+	r           io.Reader
+	done, total int64
+	report      func(done, total int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.done += int64(n)
+	if n > 0 {
+		p.report(p.done, p.total)
+	}
+	return n, err
+}
+
+func (d *Downloader) maxSize() int64 {
+	if d.MaxSize > 0 {
+		return d.MaxSize
+	}
+	return maxDownloadSize
+}
+
+// fetch returns the local path of fileID, downloading it unless a previous
+// fetch already stored it.
+func (d *Downloader) fetch(obj *Action, fileID string) (string, error) {
+	if path := obj.storedFile(fileID); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	file, err := obj.getFile(fileID)
+	if err != nil {
+		return "", err
+	}
+	if int64(file.FileSize) > d.maxSize() {
+		return "", fmt.Errorf("file %s is %d bytes, limit is %d", fileID, file.FileSize, d.maxSize())
+	}
+
+	resp, err := obj.httpClient().Get(telegramFileUrl + api + "/" + file.FilePath)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("download of %s: %s", fileID, resp.Status)
+	}
+
+	if err := os.MkdirAll(d.Dir, 0750); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempFile(d.Dir, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	var body io.Reader = io.LimitReader(resp.Body, d.maxSize()+1)
+	if d.Progress != nil {
+		body = &progressReader{r: body, total: int64(file.FileSize), report: func(done, total int64) {
+			d.Progress(fileID, done, total)
+		}}
+	}
+	n, err := io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	if n > d.maxSize() {
+		return "", fmt.Errorf("file %s is larger than %d bytes", fileID, d.maxSize())
+	}
+
+	path := filepath.Join(d.Dir, localName(fileID, file.FilePath))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, obj.storeFile(fileID, path)
+}
+
+// localName keeps the extension Telegram gave the file.
+func localName(fileID, filePath string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, fileID)
+	return name + filepath.Ext(filePath)
+}
+
+func (obj *Action) storedFile(fileID string) string {
+	path := ""
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(filesBucket)); b != nil {
+			path = string(b.Get([]byte(fileID)))
+		}
+		return nil
+	})
+	return path
+}
+
+func (obj *Action) storeFile(fileID, path string) error {
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(filesBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(fileID), []byte(path))
+	})
+}
+
+// LargestPhoto picks the biggest size of a photo, nil for an empty array.
+func LargestPhoto(photo []PhotoSize) *PhotoSize {
+	var best *PhotoSize
+	for i := range photo {
+		p := &photo[i]
+		if best == nil || p.Width*p.Height > best.Width*best.Height ||
+			(p.Width*p.Height == best.Width*best.Height && p.FileSize > best.FileSize) {
+			best = p
+		}
+	}
+	return best
+}
+
+// MediaFileID returns the file_id of the message's media, the largest size
+// for photos, or "" for messages without a file.
+func (msg *Message) MediaFileID() string {
+	if p := LargestPhoto(msg.Photo); p != nil {
+		return p.FileID
+	}
+	for _, id := range []string{
+		msg.Document.FileID, msg.Audio.FileID, msg.Voice.FileID, msg.Video.FileID,
+		msg.VideoNote.FileID, msg.Animation.FileID, msg.Sticker.FileID,
+	} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
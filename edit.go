@@ -0,0 +1,199 @@
+// edit.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// MessageRef addresses a message either by chat and message id or, for
+// messages sent via inline mode, by inline_message_id.
+type MessageRef struct {
+	ChatID          int    `json:"chat_id,omitempty"`
+	MessageID       int    `json:"message_id,omitempty"`
+	InlineMessageID string `json:"inline_message_id,omitempty"`
+}
+
+func RefOf(msg *Message) MessageRef {
+	return MessageRef{ChatID: msg.Chat.ID, MessageID: msg.MessageID}
+}
+
+// Ref points at the message that was just sent.
+func (ret *SendMessageReturn) Ref() MessageRef {
+	return RefOf(&ret.Result)
+}
+
+// RefOfCallback points at the message whose button was pressed.
+func RefOfCallback(query *CallbackQuery) MessageRef {
+	if query.InlineMessageID != "" {
+		return MessageRef{InlineMessageID: query.InlineMessageID}
+	}
+	return RefOf(&query.Message)
+}
+
+// InputMedia is one of the InputMedia* types. The type field is written by
+// MarshalJSON, media is a file_id or an HTTP URL.
+type InputMedia interface {
+	MediaType() string
+}
+
+type InputMediaPhoto struct {
+	Media     string `json:"media"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type InputMediaVideo struct {
+	Media             string `json:"media"`
+	Thumb             string `json:"thumb,omitempty"`
+	Caption           string `json:"caption,omitempty"`
+	ParseMode         string `json:"parse_mode,omitempty"`
+	Width             int    `json:"width,omitempty"`
+	Height            int    `json:"height,omitempty"`
+	Duration          int    `json:"duration,omitempty"`
+	SupportsStreaming bool   `json:"supports_streaming,omitempty"`
+}
+
+type InputMediaAnimation struct {
+	Media     string `json:"media"`
+	Thumb     string `json:"thumb,omitempty"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Duration  int    `json:"duration,omitempty"`
+}
+
+type InputMediaAudio struct {
+	Media     string `json:"media"`
+	Thumb     string `json:"thumb,omitempty"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+	Duration  int    `json:"duration,omitempty"`
+	Performer string `json:"performer,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+type InputMediaDocument struct {
+	Media     string `json:"media"`
+	Thumb     string `json:"thumb,omitempty"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+func (InputMediaPhoto) MediaType() string     { return "photo" }
+func (InputMediaVideo) MediaType() string     { return "video" }
+func (InputMediaAnimation) MediaType() string { return "animation" }
+func (InputMediaAudio) MediaType() string     { return "audio" }
+func (InputMediaDocument) MediaType() string  { return "document" }
+
+// marshalTyped marshals v with a leading "type" field.
+func marshalTyped(typ string, v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	head := `{"type":` + strconv.Quote(typ)
+	if len(b) > 2 {
+		head += ","
+	}
+	return append([]byte(head), b[1:]...), nil
+}
+
+func (m InputMediaPhoto) MarshalJSON() ([]byte, error) {
+	type plain InputMediaPhoto
+	return marshalTyped(m.MediaType(), plain(m))
+}
+
+func (m InputMediaVideo) MarshalJSON() ([]byte, error) {
+	type plain InputMediaVideo
+	return marshalTyped(m.MediaType(), plain(m))
+}
+
+func (m InputMediaAnimation) MarshalJSON() ([]byte, error) {
+	type plain InputMediaAnimation
+	return marshalTyped(m.MediaType(), plain(m))
+}
+
+func (m InputMediaAudio) MarshalJSON() ([]byte, error) {
+	type plain InputMediaAudio
+	return marshalTyped(m.MediaType(), plain(m))
+}
+
+func (m InputMediaDocument) MarshalJSON() ([]byte, error) {
+	type plain InputMediaDocument
+	return marshalTyped(m.MediaType(), plain(m))
+}
+
+type PayloadEditMessageText struct {
+	MessageRef
+	Text                  string  `json:"text"`
+	ParseMode             string  `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool    `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           *Button `json:"reply_markup,omitempty"`
+}
+
+type PayloadEditMessageCaption struct {
+	MessageRef
+	Caption     string  `json:"caption"`
+	ParseMode   string  `json:"parse_mode,omitempty"`
+	ReplyMarkup *Button `json:"reply_markup,omitempty"`
+}
+
+type PayloadEditMessageReplyMarkup struct {
+	MessageRef
+	ReplyMarkup *Button `json:"reply_markup,omitempty"`
+}
+
+type PayloadEditMessageMedia struct {
+	MessageRef
+	Media       InputMedia `json:"media"`
+	ReplyMarkup *Button    `json:"reply_markup,omitempty"`
+}
+
+// edit calls an edit method. Telegram answers with the edited message for
+// chat messages and with true for inline ones, in which case nil is returned.
+func (obj *Action) edit(method string, ref MessageRef, payload interface{}) (*Message, error) {
+	if ref.InlineMessageID != "" {
+		return nil, obj.call(method, payload, new(InlineReturn))
+	}
+	ret := new(SendMessageReturn)
+	if err := obj.call(method, payload, ret); err != nil {
+		return nil, err
+	}
+	return &ret.Result, nil
+}
+
+func (obj *Action) editMessageText(ref MessageRef, text, parseMode string, markup *Button) (*Message, error) {
+	data := PayloadEditMessageText{MessageRef: ref, Text: text, ParseMode: parseMode, ReplyMarkup: markup}
+	return obj.edit("editMessageText", ref, data)
+}
+
+func (obj *Action) editMessageCaption(ref MessageRef, caption, parseMode string, markup *Button) (*Message, error) {
+	data := PayloadEditMessageCaption{MessageRef: ref, Caption: caption, ParseMode: parseMode, ReplyMarkup: markup}
+	return obj.edit("editMessageCaption", ref, data)
+}
+
+// editMessageReplyMarkup replaces the inline keyboard, a nil markup removes it.
+func (obj *Action) editMessageReplyMarkup(ref MessageRef, markup *Button) (*Message, error) {
+	data := PayloadEditMessageReplyMarkup{MessageRef: ref, ReplyMarkup: markup}
+	return obj.edit("editMessageReplyMarkup", ref, data)
+}
+
+func (obj *Action) editMessageMedia(ref MessageRef, media InputMedia, markup *Button) (*Message, error) {
+	data := PayloadEditMessageMedia{MessageRef: ref, Media: media, ReplyMarkup: markup}
+	return obj.edit("editMessageMedia", ref, data)
+}
+
+// deleteMessage only works for chat messages, Telegram cannot delete inline ones.
+func (obj *Action) deleteMessage(ref MessageRef) error {
+	if ref.InlineMessageID != "" {
+		return errors.New("deleteMessage: inline messages cannot be deleted")
+	}
+	type Payload struct {
+		ChatID    int `json:"chat_id"`
+		MessageID int `json:"message_id"`
+	}
+	return obj.call("deleteMessage", Payload{ChatID: ref.ChatID, MessageID: ref.MessageID}, new(InlineReturn))
+}
@@ -0,0 +1,169 @@
+// entities.go
+package main
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// Entity is a MessageEntity together with the text it covers. Offsets and
+// lengths of entities count UTF-16 code units, not bytes or runes.
+type Entity struct {
+	MessageEntity
+	Text string
+}
+
+// utf16Len is the length of s as Telegram counts it.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// utf16Slice returns the part of the UTF-16 encoded text from offset on,
+// length units long, clamped to the text.
+func utf16Slice(text []uint16, offset, length int) string {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(text) {
+		offset = len(text)
+	}
+	end := offset + length
+	if end > len(text) {
+		end = len(text)
+	}
+	return string(utf16.Decode(text[offset:end]))
+}
+
+// ParseEntities returns the entities of text with their exact substrings,
+// optionally only those of the given types.
+func ParseEntities(text string, ents []MessageEntity, types ...string) []Entity {
+	encoded := utf16.Encode([]rune(text))
+	var ret []Entity
+	for _, ent := range ents {
+		if len(types) > 0 && !hasType(types, ent.Type) {
+			continue
+		}
+		ret = append(ret, Entity{MessageEntity: ent, Text: utf16Slice(encoded, ent.Offset, ent.Length)})
+	}
+	return ret
+}
+
+func hasType(types []string, t string) bool {
+	for _, typ := range types {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+// TextEntities parses the entities of the text, or of the caption for media.
+func (msg *Message) TextEntities(types ...string) []Entity {
+	if msg.Text == "" && msg.Caption != "" {
+		return ParseEntities(msg.Caption, msg.CaptionEntities, types...)
+	}
+	return ParseEntities(msg.Text, msg.Entities, types...)
+}
+
+func entityTexts(ents []Entity) []string {
+	var ret []string
+	for _, ent := range ents {
+		ret = append(ret, ent.Text)
+	}
+	return ret
+}
+
+// Mentions returns the @usernames of the message.
+func (msg *Message) Mentions() []string {
+	return entityTexts(msg.TextEntities("mention"))
+}
+
+func (msg *Message) Hashtags() []string {
+	return entityTexts(msg.TextEntities("hashtag"))
+}
+
+func (msg *Message) Cashtags() []string {
+	return entityTexts(msg.TextEntities("cashtag"))
+}
+
+// BotCommands returns the commands as written, /start@ThisBot included.
+func (msg *Message) BotCommands() []string {
+	return entityTexts(msg.TextEntities("bot_command"))
+}
+
+func (msg *Message) Emails() []string {
+	return entityTexts(msg.TextEntities("email"))
+}
+
+// URLs returns plain urls of the text and the targets of text_links.
+func (msg *Message) URLs() []string {
+	var ret []string
+	for _, ent := range msg.TextEntities("url", "text_link") {
+		if ent.Type == "text_link" {
+			ret = append(ret, ent.URL)
+		} else {
+			ret = append(ret, ent.Text)
+		}
+	}
+	return ret
+}
+
+// TextLinks returns the text_link entities, their URL field is the target.
+func (msg *Message) TextLinks() []Entity {
+	return msg.TextEntities("text_link")
+}
+
+// TextMentions returns mentions of users without username, the User field
+// of each entity is the mentioned user.
+func (msg *Message) TextMentions() []Entity {
+	return msg.TextEntities("text_mention")
+}
+
+// EntityBuilder builds outgoing text together with its entity list.
+type EntityBuilder struct {
+	buf      strings.Builder
+	length   int
+	Entities []MessageEntity
+}
+
+// Text appends s without an entity.
+func (b *EntityBuilder) Text(s string) *EntityBuilder {
+	b.buf.WriteString(s)
+	b.length += utf16Len(s)
+	return b
+}
+
+// Entity appends s covered by an entity of type typ.
+func (b *EntityBuilder) Entity(typ, s string) *EntityBuilder {
+	return b.add(MessageEntity{Type: typ}, s)
+}
+
+func (b *EntityBuilder) Link(s, url string) *EntityBuilder {
+	return b.add(MessageEntity{Type: "text_link", URL: url}, s)
+}
+
+func (b *EntityBuilder) TextMention(s string, user User) *EntityBuilder {
+	return b.add(MessageEntity{Type: "text_mention", User: &user}, s)
+}
+
+func (b *EntityBuilder) Pre(s, language string) *EntityBuilder {
+	return b.add(MessageEntity{Type: "pre", Language: language}, s)
+}
+
+func (b *EntityBuilder) add(ent MessageEntity, s string) *EntityBuilder {
+	ent.Offset = b.length
+	ent.Length = utf16Len(s)
+	b.Text(s)
+	if ent.Length > 0 {
+		b.Entities = append(b.Entities, ent)
+	}
+	return b
+}
+
+func (b *EntityBuilder) String() string {
+	return b.buf.String()
+}
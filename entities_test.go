@@ -0,0 +1,101 @@
+// entities_test.go
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEntities(t *testing.T) {
+	// H i ␠ 😀(2 units) ␠ @bob ␠ #tag
+	text := "Hi 😀 @bob #tag"
+	ents := []MessageEntity{
+		{Type: "mention", Offset: 6, Length: 4},
+		{Type: "hashtag", Offset: 11, Length: 4},
+		{Type: "bold", Offset: 3, Length: 2},
+		{Type: "italic", Offset: 13, Length: 10},
+	}
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{"all", nil, []string{"@bob", "#tag", "😀", "ag"}},
+		{"one type", []string{"mention"}, []string{"@bob"}},
+		{"two types", []string{"hashtag", "bold"}, []string{"#tag", "😀"}},
+		{"no match", []string{"url"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, ent := range ParseEntities(text, ents, tt.types...) {
+			got = append(got, ent.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEntityBuilder(t *testing.T) {
+	b := &EntityBuilder{}
+	b.Text("😀 ").Entity("bold", "hi").Text(" ").Link("x", "https://example.com")
+	if got, want := b.String(), "😀 hi x"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+	want := []MessageEntity{
+		{Type: "bold", Offset: 3, Length: 2},
+		{Type: "text_link", Offset: 6, Length: 1, URL: "https://example.com"},
+	}
+	if !reflect.DeepEqual(b.Entities, want) {
+		t.Errorf("entities = %+v, want %+v", b.Entities, want)
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		ents []MessageEntity
+		want []*Command
+	}{
+		{
+			"mention and two commands",
+			"/start@MyBot a b /help x",
+			[]MessageEntity{{Type: "bot_command", Offset: 0, Length: 12}, {Type: "bot_command", Offset: 17, Length: 5}},
+			[]*Command{
+				{Name: "start", Mention: "MyBot", Args: []string{"a", "b"}, RawArgs: "a b"},
+				{Name: "help", Args: []string{"x"}, RawArgs: "x"},
+			},
+		},
+		{
+			"offset after emoji",
+			"😀 /go now",
+			[]MessageEntity{{Type: "bot_command", Offset: 3, Length: 3}},
+			[]*Command{{Name: "go", Args: []string{"now"}, RawArgs: "now"}},
+		},
+		{
+			"no arguments",
+			"/ping",
+			[]MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			[]*Command{{Name: "ping", Args: []string{}}},
+		},
+		{
+			"entity past the text is skipped",
+			"/a",
+			[]MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+			nil,
+		},
+		{
+			"other entities are not commands",
+			"@bob hi",
+			[]MessageEntity{{Type: "mention", Offset: 0, Length: 4}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		got := parseCommands(&Message{Text: tt.text, Entities: tt.ents})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// events.go
+package main
+
+import (
+	"strconv"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const migrationsBucket = "Migrations"
+
+type MembersHandler func(obj *Action, msg *Message, users []User)
+type MemberHandler func(obj *Action, msg *Message, user *User)
+type TitleHandler func(obj *Action, msg *Message, title string)
+type PhotoHandler func(obj *Action, msg *Message, photo []PhotoSize)
+type MigrateHandler func(obj *Action, msg *Message, fromChatID, toChatID int)
+
+// ChatEvents holds the handlers for service messages about the chat itself.
+// Messages a handler took are not passed on to OnMessage.
+type ChatEvents struct {
+	OnNewMembers        MembersHandler
+	OnLeftMember        MemberHandler
+	OnNewTitle          TitleHandler
+	OnNewPhoto          PhotoHandler
+	OnDeletePhoto       MessageHandler
+	OnGroupCreated      MessageHandler
+	OnSupergroupCreated MessageHandler
+	OnChannelCreated    MessageHandler
+	// OnMigrate runs after every chat ID stored in bolt was rewritten.
+	OnMigrate MigrateHandler
+}
+
+func (e *ChatEvents) any() bool {
+	return e.OnNewMembers != nil || e.OnLeftMember != nil || e.OnNewTitle != nil ||
+		e.OnNewPhoto != nil || e.OnDeletePhoto != nil || e.OnGroupCreated != nil ||
+		e.OnSupergroupCreated != nil || e.OnChannelCreated != nil || e.OnMigrate != nil
+}
+
+// handle reports whether msg was a chat event consumed by a handler. Events
+// without a handler go on to OnMessage, migrations are always consumed, the
+// stored chat IDs have to be rewritten either way.
+func (e *ChatEvents) handle(obj *Action, msg *Message) bool {
+	switch {
+	case len(msg.NewChatMembers) > 0:
+		if e.OnNewMembers == nil {
+			return false
+		}
+		e.OnNewMembers(obj, msg, msg.NewChatMembers)
+	case msg.LeftChatMember.ID != 0:
+		if e.OnLeftMember == nil {
+			return false
+		}
+		e.OnLeftMember(obj, msg, &msg.LeftChatMember)
+	case msg.NewChatTitle != "":
+		if e.OnNewTitle == nil {
+			return false
+		}
+		e.OnNewTitle(obj, msg, msg.NewChatTitle)
+	case len(msg.NewChatPhoto) > 0:
+		if e.OnNewPhoto == nil {
+			return false
+		}
+		e.OnNewPhoto(obj, msg, msg.NewChatPhoto)
+	case msg.DeleteChatPhoto:
+		if e.OnDeletePhoto == nil {
+			return false
+		}
+		e.OnDeletePhoto(obj, msg)
+	case msg.GroupChatCreated:
+		if e.OnGroupCreated == nil {
+			return false
+		}
+		e.OnGroupCreated(obj, msg)
+	case msg.SupergroupChatCreated:
+		if e.OnSupergroupCreated == nil {
+			return false
+		}
+		e.OnSupergroupCreated(obj, msg)
+	case msg.ChannelChatCreated:
+		if e.OnChannelCreated == nil {
+			return false
+		}
+		e.OnChannelCreated(obj, msg)
+	case msg.MigrateToChatID != 0:
+		e.migrate(obj, msg, msg.Chat.ID, msg.MigrateToChatID)
+	case msg.MigrateFromChatID != 0:
+		e.migrate(obj, msg, msg.MigrateFromChatID, msg.Chat.ID)
+	default:
+		return false
+	}
+	return true
+}
+
+// migrate runs once per migration, Telegram announces it both in the old
+// group and in the new supergroup.
+func (e *ChatEvents) migrate(obj *Action, msg *Message, from, to int) {
+	done, err := obj.migrateChat(from, to)
+	Dbg(err)
+	if done && e.OnMigrate != nil {
+		e.OnMigrate(obj, msg, from, to)
+	}
+}
+
+// ChatMigration rewrites the chat ID from to to in whatever a feature keeps in bolt.
+type ChatMigration func(tx *bolt.Tx, from, to int) error
+
+var chatMigrations []ChatMigration
+
+// registerChatMigration adds fn to the rewrites migrateChat runs.
+func registerChatMigration(fn ChatMigration) {
+	chatMigrations = append(chatMigrations, fn)
+}
+
+// migrateChat rewrites every chat ID stored in bolt in one transaction and
+// remembers the migration. It reports false when from was migrated before.
+func (obj *Action) migrateChat(from, to int) (bool, error) {
+	done := false
+	err := obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(migrationsBucket))
+		if err != nil {
+			return err
+		}
+		key := []byte(strconv.Itoa(from))
+		if b.Get(key) != nil {
+			return nil
+		}
+		for _, fn := range chatMigrations {
+			if err := fn(tx, from, to); err != nil {
+				return err
+			}
+		}
+		done = true
+		return b.Put(key, []byte(strconv.Itoa(to)))
+	})
+	return done, err
+}
+
+// migratedChat follows recorded migrations from chatID to the current chat.
+func (obj *Action) migratedChat(chatID int) int {
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(migrationsBucket))
+		if b == nil {
+			return nil
+		}
+		seen := map[int]bool{chatID: true}
+		for v := b.Get([]byte(strconv.Itoa(chatID))); v != nil; v = b.Get([]byte(strconv.Itoa(chatID))) {
+			chatID, _ = strconv.Atoi(string(v))
+			if seen[chatID] {
+				break
+			}
+			seen[chatID] = true
+		}
+		return nil
+	})
+	return chatID
+}
@@ -0,0 +1,199 @@
+// format.go
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+	// ParseModeMarkdown is the legacy mode, it cannot escape inside entities
+	// so delimiter characters there are dropped.
+	ParseModeMarkdown = "Markdown"
+	// ParseModeEntities sends plain text with an entity list instead.
+	ParseModeEntities = ""
+)
+
+var (
+	htmlEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	mdV2Escaper   = strings.NewReplacer(markdownV2Pairs("_*[]()~`>#+-=|{}.!\\")...)
+	mdV2Code      = strings.NewReplacer(markdownV2Pairs("`\\")...)
+	mdV2Url       = strings.NewReplacer(markdownV2Pairs(")\\")...)
+	mdEscaper     = strings.NewReplacer(markdownV2Pairs("_*`[")...)
+	mdStripBold   = strings.NewReplacer("*", "")
+	mdStripItalic = strings.NewReplacer("_", "")
+	mdStripCode   = strings.NewReplacer("`", "")
+	mdStripLink   = strings.NewReplacer("]", "")
+	// legacy Markdown has no escapes in urls, ')' is percent-encoded instead
+	mdUrl = strings.NewReplacer(")", "%29")
+)
+
+func markdownV2Pairs(chars string) []string {
+	var pairs []string
+	for _, c := range chars {
+		pairs = append(pairs, string(c), `\`+string(c))
+	}
+	return pairs
+}
+
+type formatSpan struct {
+	kind string
+	text string
+	url  string
+	user User
+	lang string
+}
+
+// Formatter collects text and formatted spans and renders them in any parse
+// mode with the escaping that mode needs, so user text can be embedded as is.
+type Formatter struct {
+	spans []formatSpan
+}
+
+func NewFormat() *Formatter {
+	return &Formatter{}
+}
+
+func (f *Formatter) add(span formatSpan) *Formatter {
+	f.spans = append(f.spans, span)
+	return f
+}
+
+func (f *Formatter) Text(s string) *Formatter   { return f.add(formatSpan{kind: "text", text: s}) }
+func (f *Formatter) Bold(s string) *Formatter   { return f.add(formatSpan{kind: "bold", text: s}) }
+func (f *Formatter) Italic(s string) *Formatter { return f.add(formatSpan{kind: "italic", text: s}) }
+func (f *Formatter) Code(s string) *Formatter   { return f.add(formatSpan{kind: "code", text: s}) }
+
+func (f *Formatter) Pre(s, language string) *Formatter {
+	return f.add(formatSpan{kind: "pre", text: s, lang: language})
+}
+
+func (f *Formatter) Link(s, url string) *Formatter {
+	return f.add(formatSpan{kind: "text_link", text: s, url: url})
+}
+
+// Mention links s to user, it works for users without username too.
+func (f *Formatter) Mention(s string, user User) *Formatter {
+	return f.add(formatSpan{kind: "text_mention", text: s, user: user})
+}
+
+func mentionUrl(user User) string {
+	return "tg://user?id=" + strconv.Itoa(user.ID)
+}
+
+func (f *Formatter) HTML() string {
+	var b strings.Builder
+	for _, s := range f.spans {
+		text := htmlEscaper.Replace(s.text)
+		switch s.kind {
+		case "bold":
+			b.WriteString("<b>" + text + "</b>")
+		case "italic":
+			b.WriteString("<i>" + text + "</i>")
+		case "code":
+			b.WriteString("<code>" + text + "</code>")
+		case "pre":
+			if s.lang != "" {
+				b.WriteString(`<pre><code class="language-` + htmlEscaper.Replace(s.lang) + `">` + text + "</code></pre>")
+			} else {
+				b.WriteString("<pre>" + text + "</pre>")
+			}
+		case "text_link":
+			b.WriteString(`<a href="` + htmlEscaper.Replace(s.url) + `">` + text + "</a>")
+		case "text_mention":
+			b.WriteString(`<a href="` + mentionUrl(s.user) + `">` + text + "</a>")
+		default:
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func (f *Formatter) MarkdownV2() string {
+	var b strings.Builder
+	for _, s := range f.spans {
+		switch s.kind {
+		case "bold":
+			b.WriteString("*" + mdV2Escaper.Replace(s.text) + "*")
+		case "italic":
+			b.WriteString("_" + mdV2Escaper.Replace(s.text) + "_")
+		case "code":
+			b.WriteString("`" + mdV2Code.Replace(s.text) + "`")
+		case "pre":
+			b.WriteString("```" + s.lang + "\n" + mdV2Code.Replace(s.text) + "\n```")
+		case "text_link":
+			b.WriteString("[" + mdV2Escaper.Replace(s.text) + "](" + mdV2Url.Replace(s.url) + ")")
+		case "text_mention":
+			b.WriteString("[" + mdV2Escaper.Replace(s.text) + "](" + mentionUrl(s.user) + ")")
+		default:
+			b.WriteString(mdV2Escaper.Replace(s.text))
+		}
+	}
+	return b.String()
+}
+
+// Markdown renders the legacy parse mode.
+func (f *Formatter) Markdown() string {
+	var b strings.Builder
+	for _, s := range f.spans {
+		switch s.kind {
+		case "bold":
+			b.WriteString("*" + mdStripBold.Replace(s.text) + "*")
+		case "italic":
+			b.WriteString("_" + mdStripItalic.Replace(s.text) + "_")
+		case "code":
+			b.WriteString("`" + mdStripCode.Replace(s.text) + "`")
+		case "pre":
+			b.WriteString("```" + s.lang + "\n" + mdStripCode.Replace(s.text) + "\n```")
+		case "text_link":
+			b.WriteString("[" + mdStripLink.Replace(s.text) + "](" + mdUrl.Replace(s.url) + ")")
+		case "text_mention":
+			b.WriteString("[" + mdStripLink.Replace(s.text) + "](" + mentionUrl(s.user) + ")")
+		default:
+			b.WriteString(mdEscaper.Replace(s.text))
+		}
+	}
+	return b.String()
+}
+
+// Entities renders plain text and the entities that format it.
+func (f *Formatter) Entities() (string, []MessageEntity) {
+	b := &EntityBuilder{}
+	for _, s := range f.spans {
+		switch s.kind {
+		case "text":
+			b.Text(s.text)
+		case "pre":
+			b.Pre(s.text, s.lang)
+		case "text_link":
+			b.Link(s.text, s.url)
+		case "text_mention":
+			b.TextMention(s.text, s.user)
+		default:
+			b.Entity(s.kind, s.text)
+		}
+	}
+	return b.String(), b.Entities
+}
+
+// Render returns the text for parseMode, entities are only returned for
+// ParseModeEntities.
+func (f *Formatter) Render(parseMode string) (string, []MessageEntity) {
+	switch parseMode {
+	case ParseModeHTML:
+		return f.HTML(), nil
+	case ParseModeMarkdownV2:
+		return f.MarkdownV2(), nil
+	case ParseModeMarkdown:
+		return f.Markdown(), nil
+	}
+	return f.Entities()
+}
+
+// Apply sets text, parse mode and entities of data.
+func (f *Formatter) Apply(data *PayloadMesageSend, parseMode string) {
+	data.Text, data.Entities = f.Render(parseMode)
+	data.ParseMode = parseMode
+}
@@ -0,0 +1,99 @@
+// format_test.go
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatterEscaping(t *testing.T) {
+	tests := []struct {
+		name       string
+		f          *Formatter
+		html       string
+		markdownV2 string
+		markdown   string
+	}{
+		{
+			"plain text",
+			NewFormat().Text("1<2 & a_b*c [x] (y) `z` \\"),
+			"1&lt;2 &amp; a_b*c [x] (y) `z` \\",
+			"1<2 & a\\_b\\*c \\[x\\] \\(y\\) \\`z\\` \\\\",
+			"1<2 & a\\_b\\*c \\[x] (y) \\`z\\` \\",
+		},
+		{
+			"bold",
+			NewFormat().Bold("a*b_c<"),
+			"<b>a*b_c&lt;</b>",
+			"*a\\*b\\_c<*",
+			"*ab_c<*",
+		},
+		{
+			"italic",
+			NewFormat().Italic("a_b*c"),
+			"<i>a_b*c</i>",
+			"_a\\_b\\*c_",
+			"_ab*c_",
+		},
+		{
+			"code",
+			NewFormat().Code("a`b\\c<&"),
+			"<code>a`b\\c&lt;&amp;</code>",
+			"`a\\`b\\\\c<&`",
+			"`ab\\c<&`",
+		},
+		{
+			"pre",
+			NewFormat().Pre("a<b`", "go"),
+			"<pre><code class=\"language-go\">a&lt;b`</code></pre>",
+			"```go\na<b\\`\n```",
+			"```go\na<b\n```",
+		},
+		{
+			"link with ) in url",
+			NewFormat().Link("x)[y]", "http://x/(a)"),
+			"<a href=\"http://x/(a)\">x)[y]</a>",
+			"[x\\)\\[y\\]](http://x/(a\\))",
+			"[x)[y](http://x/(a%29)",
+		},
+		{
+			"link with & and quotes in url",
+			NewFormat().Link("q", "http://x/?a=1&b=\"2\""),
+			"<a href=\"http://x/?a=1&amp;b=&quot;2&quot;\">q</a>",
+			"[q](http://x/?a=1&b=\"2\")",
+			"[q](http://x/?a=1&b=\"2\")",
+		},
+		{
+			"mention",
+			NewFormat().Mention("<me>_", User{ID: 42}),
+			"<a href=\"tg://user?id=42\">&lt;me&gt;_</a>",
+			"[<me\\>\\_](tg://user?id=42)",
+			"[<me>_](tg://user?id=42)",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.f.HTML(); got != tt.html {
+			t.Errorf("%s: HTML = %q, want %q", tt.name, got, tt.html)
+		}
+		if got := tt.f.MarkdownV2(); got != tt.markdownV2 {
+			t.Errorf("%s: MarkdownV2 = %q, want %q", tt.name, got, tt.markdownV2)
+		}
+		if got := tt.f.Markdown(); got != tt.markdown {
+			t.Errorf("%s: Markdown = %q, want %q", tt.name, got, tt.markdown)
+		}
+	}
+}
+
+func TestFormatterEntities(t *testing.T) {
+	text, ents := NewFormat().Text("a<b ").Bold("😀*").Link("x", "http://x/(a)").Entities()
+	if want := "a<b 😀*x"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	want := []MessageEntity{
+		{Type: "bold", Offset: 4, Length: 3},
+		{Type: "text_link", Offset: 7, Length: 1, URL: "http://x/(a)"},
+	}
+	if !reflect.DeepEqual(ents, want) {
+		t.Errorf("entities = %+v, want %+v", ents, want)
+	}
+}
@@ -0,0 +1,261 @@
+// games.go
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	leaderboardBucket = "Leaderboard"
+	leaderboardSep    = "|"
+	gameSigParam      = "sig"
+)
+
+var ErrGameForged = errors.New("game url signature mismatch")
+
+type GameHighScore struct {
+	Position int  `json:"position"`
+	User     User `json:"user"`
+	Score    int  `json:"score"`
+}
+
+type GameHighScoresReturn struct {
+	Result      []GameHighScore `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Ok          bool            `json:"ok"`
+	Description string          `json:"description"`
+}
+
+type leaderboardEntry struct {
+	Stored int64           `json:"stored"`
+	Scores []GameHighScore `json:"scores"`
+}
+
+func init() {
+	registerChatMigration(migrateLeaderboard)
+}
+
+func (obj *Action) sendGame(chatID int, shortName string, markup *Button) (*SendMessageReturn, error) {
+	type Payload struct {
+		ChatID        int     `json:"chat_id"`
+		GameShortName string  `json:"game_short_name"`
+		ReplyMarkup   *Button `json:"reply_markup,omitempty"`
+	}
+	ret := new(SendMessageReturn)
+	err := obj.call("sendGame", Payload{ChatID: chatID, GameShortName: shortName, ReplyMarkup: markup}, ret)
+	return ret, err
+}
+
+// HandleGame answers presses on the Play button of shortName with gameUrl.
+// With r.GameSecret set the player and the game message are appended as
+// user_id and chat_id with message_id, or inline_message_id, and signed in
+// a sig parameter, so the game can report scores back once VerifyGame
+// accepted them. Without GameSecret gameUrl is served as is.
+func (r *CallbackRouter) HandleGame(shortName, gameUrl string) {
+	if r.games == nil {
+		r.games = make(map[string]string)
+	}
+	r.games[shortName] = gameUrl
+}
+
+func (r *CallbackRouter) gameAnswer(query *CallbackQuery) *CallbackAnswer {
+	gameUrl, ok := r.games[query.GameShortName]
+	if !ok {
+		return &CallbackAnswer{Text: "Unknown game", ShowAlert: true}
+	}
+	if len(r.GameSecret) == 0 {
+		return &CallbackAnswer{Url: gameUrl}
+	}
+	u, err := url.Parse(gameUrl)
+	if err != nil {
+		return &CallbackAnswer{Text: "Game unavailable", ShowAlert: true}
+	}
+	signed := url.Values{}
+	signed.Set("user_id", strconv.Itoa(query.From.ID))
+	ref := RefOfCallback(query)
+	if ref.InlineMessageID != "" {
+		signed.Set("inline_message_id", ref.InlineMessageID)
+	} else {
+		signed.Set("chat_id", strconv.Itoa(ref.ChatID))
+		signed.Set("message_id", strconv.Itoa(ref.MessageID))
+	}
+	params := u.Query()
+	for k := range signed {
+		params.Set(k, signed.Get(k))
+	}
+	params.Set(gameSigParam, gameSign(r.GameSecret, signed))
+	u.RawQuery = params.Encode()
+	return &CallbackAnswer{Url: u.String()}
+}
+
+// gameSign is an HMAC-SHA256 over the player and message params only, the
+// game url may carry parameters of its own.
+func gameSign(secret []byte, signed url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyGame checks the sig parameter that HandleGame added to the game url
+// and returns the player and the game message it was issued for.
+func VerifyGame(secret []byte, params url.Values) (int, MessageRef, error) {
+	ref := MessageRef{}
+	signed := url.Values{}
+	for _, k := range []string{"user_id", "chat_id", "message_id", "inline_message_id"} {
+		if v := params.Get(k); v != "" {
+			signed.Set(k, v)
+		}
+	}
+	want := gameSign(secret, signed)
+	if len(secret) == 0 || !hmac.Equal([]byte(params.Get(gameSigParam)), []byte(want)) {
+		return 0, ref, ErrGameForged
+	}
+	userID, err := strconv.Atoi(signed.Get("user_id"))
+	if err != nil {
+		return 0, ref, ErrGameForged
+	}
+	if id := signed.Get("inline_message_id"); id != "" {
+		ref.InlineMessageID = id
+		return userID, ref, nil
+	}
+	if ref.ChatID, err = strconv.Atoi(signed.Get("chat_id")); err != nil {
+		return 0, ref, ErrGameForged
+	}
+	if ref.MessageID, err = strconv.Atoi(signed.Get("message_id")); err != nil {
+		return 0, ref, ErrGameForged
+	}
+	return userID, ref, nil
+}
+
+// leaderboardPrefix starts the keys of all cached neighbourhoods of ref.
+func leaderboardPrefix(ref MessageRef) []byte {
+	if ref.InlineMessageID != "" {
+		return []byte(ref.InlineMessageID + leaderboardSep)
+	}
+	return []byte(strconv.Itoa(ref.ChatID) + ":" + strconv.Itoa(ref.MessageID) + leaderboardSep)
+}
+
+// leaderboardKey is per player, Telegram answers with the scores around
+// the player asking.
+func leaderboardKey(ref MessageRef, userID int) []byte {
+	return append(leaderboardPrefix(ref), strconv.Itoa(userID)...)
+}
+
+func migrateLeaderboard(tx *bolt.Tx, from, to int) error {
+	b := tx.Bucket([]byte(leaderboardBucket))
+	if b == nil {
+		return nil
+	}
+	prefix := strconv.Itoa(from) + ":"
+	moved := make(map[string][]byte)
+	b.ForEach(func(k, v []byte) error {
+		if strings.HasPrefix(string(k), prefix) {
+			moved[string(k)] = append([]byte{}, v...)
+		}
+		return nil
+	})
+	for k, v := range moved {
+		if err := b.Delete([]byte(k)); err != nil {
+			return err
+		}
+		if err := b.Put([]byte(strconv.Itoa(to)+":"+strings.TrimPrefix(k, prefix)), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setGameScore sets the score of userID in the game message ref and drops
+// the cached leaderboards of that message for every player.
+func (obj *Action) setGameScore(userID, score int, ref MessageRef, force, disableEditMessage bool) (*Message, error) {
+	type Payload struct {
+		UserID int `json:"user_id"`
+		Score  int `json:"score"`
+		MessageRef
+		Force              bool `json:"force,omitempty"`
+		DisableEditMessage bool `json:"disable_edit_message,omitempty"`
+	}
+	data := Payload{UserID: userID, Score: score, MessageRef: ref, Force: force, DisableEditMessage: disableEditMessage}
+	msg, err := obj.edit("setGameScore", ref, data)
+	if err != nil {
+		return nil, err
+	}
+	obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(leaderboardBucket))
+		if b == nil {
+			return nil
+		}
+		prefix := leaderboardPrefix(ref)
+		var stale [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			stale = append(stale, append([]byte{}, k...))
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return msg, nil
+}
+
+// getGameHighScores returns the scores of userID and the players next to
+// them in the game message ref, and caches them in bolt.
+func (obj *Action) getGameHighScores(userID int, ref MessageRef) ([]GameHighScore, error) {
+	type Payload struct {
+		UserID int `json:"user_id"`
+		MessageRef
+	}
+	ret := new(GameHighScoresReturn)
+	if err := obj.call("getGameHighScores", Payload{UserID: userID, MessageRef: ref}, ret); err != nil {
+		return nil, err
+	}
+	v, err := json.Marshal(leaderboardEntry{Stored: time.Now().Unix(), Scores: ret.Result})
+	if err != nil {
+		return nil, err
+	}
+	err = obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(leaderboardBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put(leaderboardKey(ref, userID), v)
+	})
+	return ret.Result, err
+}
+
+// leaderboard serves the cached high scores around userID in ref while they
+// are younger than maxAge and asks Telegram otherwise.
+func (obj *Action) leaderboard(userID int, ref MessageRef, maxAge time.Duration) ([]GameHighScore, error) {
+	var cached *leaderboardEntry
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(leaderboardBucket))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(leaderboardKey(ref, userID)); v != nil {
+			entry := new(leaderboardEntry)
+			if json.Unmarshal(v, entry) == nil {
+				cached = entry
+			}
+		}
+		return nil
+	})
+	if cached != nil && time.Since(time.Unix(cached.Stored, 0)) < maxAge {
+		return cached.Scores, nil
+	}
+	return obj.getGameHighScores(userID, ref)
+}
@@ -0,0 +1,46 @@
+// games_test.go
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGameAnswer(t *testing.T) {
+	query := &CallbackQuery{GameShortName: "snake", From: User{ID: 7}, InlineMessageID: "AbC"}
+
+	r := &CallbackRouter{}
+	r.HandleGame("snake", "https://example.com/snake?lvl=2")
+	if got := r.gameAnswer(query).Url; got != "https://example.com/snake?lvl=2" {
+		t.Errorf("without GameSecret url = %q", got)
+	}
+
+	r.GameSecret = []byte("game")
+	u, err := url.Parse(r.gameAnswer(query).Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := u.Query()
+	if params.Get("lvl") != "2" {
+		t.Errorf("own parameters of the game url lost: %q", u)
+	}
+	userID, ref, err := VerifyGame(r.GameSecret, params)
+	if err != nil || userID != 7 || ref.InlineMessageID != "AbC" {
+		t.Errorf("VerifyGame = %d, %+v, %v", userID, ref, err)
+	}
+
+	forged := url.Values{}
+	for k := range params {
+		forged.Set(k, params.Get(k))
+	}
+	forged.Set("user_id", "8")
+	if _, _, err := VerifyGame(r.GameSecret, forged); err != ErrGameForged {
+		t.Errorf("forged user_id: err = %v", err)
+	}
+	if _, _, err := VerifyGame([]byte("other"), params); err != ErrGameForged {
+		t.Errorf("other secret: err = %v", err)
+	}
+	if got := r.gameAnswer(&CallbackQuery{GameShortName: "chess"}); !got.ShowAlert {
+		t.Errorf("unknown game answered with %+v", got)
+	}
+}
@@ -0,0 +1,161 @@
+// history.go
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const historyBucket = "History"
+
+// Revision is one version of a message's text. The first revision is the
+// message as sent, Date is its date there and the edit_date afterwards.
+type Revision struct {
+	Text     string          `json:"text"`
+	Date     int             `json:"date"`
+	Entities []MessageEntity `json:"entities"`
+}
+
+func init() {
+	registerChatMigration(migrateHistory)
+}
+
+func historyKey(ref MessageRef) []byte {
+	return []byte(strconv.Itoa(ref.ChatID) + ":" + strconv.Itoa(ref.MessageID))
+}
+
+// TrackRevisions records msg as the newest revision of its message before
+// handing it to h, which may be nil. Register it for both new and edited
+// messages.
+func TrackRevisions(h MessageHandler) MessageHandler {
+	return func(obj *Action, msg *Message) {
+		Dbg(obj.recordRevision(msg))
+		if h != nil {
+			h(obj, msg)
+		}
+	}
+}
+
+func (obj *Action) recordRevision(msg *Message) error {
+	rev := Revision{Text: msg.Text, Date: msg.Date, Entities: msg.Entities}
+	if msg.Text == "" {
+		rev.Text, rev.Entities = msg.Caption, msg.CaptionEntities
+	}
+	if msg.EditDate != 0 {
+		rev.Date = msg.EditDate
+	}
+	v, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(historyBucket))
+		if err != nil {
+			return err
+		}
+		b, err := root.CreateBucketIfNotExists(historyKey(RefOf(msg)))
+		if err != nil {
+			return err
+		}
+		// neither the same update delivered twice nor an edit that left
+		// text and entities alone, a live location update for one, adds a
+		// revision
+		if _, last := b.Cursor().Last(); last != nil {
+			prev := Revision{}
+			if json.Unmarshal(last, &prev) == nil && prev.sameContent(rev) {
+				return nil
+			}
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return b.Put(key, v)
+	})
+}
+
+func (rev Revision) sameContent(other Revision) bool {
+	if rev.Text != other.Text || len(rev.Entities) != len(other.Entities) {
+		return false
+	}
+	if len(rev.Entities) == 0 {
+		return true
+	}
+	a, _ := json.Marshal(rev.Entities)
+	b, _ := json.Marshal(other.Entities)
+	return string(a) == string(b)
+}
+
+// messageHistory returns every recorded revision of ref, oldest first.
+func (obj *Action) messageHistory(ref MessageRef) ([]Revision, error) {
+	var revs []Revision
+	err := obj.Bolt.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte(historyBucket))
+		if root == nil {
+			return nil
+		}
+		b := root.Bucket(historyKey(ref))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			rev := Revision{}
+			if err := json.Unmarshal(v, &rev); err != nil {
+				return err
+			}
+			revs = append(revs, rev)
+			return nil
+		})
+	})
+	return revs, err
+}
+
+// rerun is an OnEditedMessage handler that routes an edited message again
+// when it contains commands, so fixing a typo in a command runs it.
+func (r *CommandRouter) rerun(obj *Action, msg *Message) {
+	if len(parseCommands(msg)) > 0 {
+		r.route(obj, msg)
+	}
+}
+
+func migrateHistory(tx *bolt.Tx, from, to int) error {
+	root := tx.Bucket([]byte(historyBucket))
+	if root == nil {
+		return nil
+	}
+	prefix := strconv.Itoa(from) + ":"
+	var names []string
+	root.ForEach(func(k, v []byte) error {
+		if v == nil && strings.HasPrefix(string(k), prefix) {
+			names = append(names, string(k))
+		}
+		return nil
+	})
+	for _, name := range names {
+		old := root.Bucket([]byte(name))
+		moved, err := root.CreateBucketIfNotExists([]byte(strconv.Itoa(to) + ":" + strings.TrimPrefix(name, prefix)))
+		if err != nil {
+			return err
+		}
+		err = old.ForEach(func(k, v []byte) error {
+			return moved.Put(k, v)
+		})
+		if err != nil {
+			return err
+		}
+		if err := moved.SetSequence(old.Sequence()); err != nil {
+			return err
+		}
+		if err := root.DeleteBucket([]byte(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
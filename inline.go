@@ -0,0 +1,344 @@
+// inline.go
+package main
+
+import (
+	"encoding/json"
+
+	. "github.com/ulvham/helper"
+)
+
+// InlineQueryResult is any of the InlineQueryResult* types below. The type
+// field is written by InlineResults from ResultType, so it is not a field.
+type InlineQueryResult interface {
+	ResultType() string
+}
+
+// InputMessageContent is the message sent instead of the result itself.
+type InputMessageContent interface {
+	inputMessageContent()
+}
+
+type InputTextMessageContent struct {
+	MessageText           string `json:"message_text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+}
+
+type InputLocationMessageContent struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	LivePeriod int     `json:"live_period,omitempty"`
+}
+
+type InputVenueMessageContent struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Title        string  `json:"title"`
+	Address      string  `json:"address"`
+	FoursquareID string  `json:"foursquare_id,omitempty"`
+}
+
+type InputContactMessageContent struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name,omitempty"`
+}
+
+func (InputTextMessageContent) inputMessageContent()     {}
+func (InputLocationMessageContent) inputMessageContent() {}
+func (InputVenueMessageContent) inputMessageContent()    {}
+func (InputContactMessageContent) inputMessageContent()  {}
+
+type InlineQueryResultArticle struct {
+	ID                  string              `json:"id"`
+	Title               string              `json:"title"`
+	InputMessageContent InputMessageContent `json:"input_message_content"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	Url                 string              `json:"url,omitempty"`
+	HideUrl             bool                `json:"hide_url,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	ThumbUrl            string              `json:"thumb_url,omitempty"`
+	ThumbWidth          int                 `json:"thumb_width,omitempty"`
+	ThumbHeight         int                 `json:"thumb_height,omitempty"`
+}
+
+type InlineQueryResultPhoto struct {
+	ID                  string              `json:"id"`
+	PhotoUrl            string              `json:"photo_url"`
+	ThumbUrl            string              `json:"thumb_url"`
+	PhotoWidth          int                 `json:"photo_width,omitempty"`
+	PhotoHeight         int                 `json:"photo_height,omitempty"`
+	Title               string              `json:"title,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultGif struct {
+	ID                  string              `json:"id"`
+	GifUrl              string              `json:"gif_url"`
+	GifWidth            int                 `json:"gif_width,omitempty"`
+	GifHeight           int                 `json:"gif_height,omitempty"`
+	GifDuration         int                 `json:"gif_duration,omitempty"`
+	ThumbUrl            string              `json:"thumb_url"`
+	Title               string              `json:"title,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultMpeg4Gif struct {
+	ID                  string              `json:"id"`
+	Mpeg4Url            string              `json:"mpeg4_url"`
+	Mpeg4Width          int                 `json:"mpeg4_width,omitempty"`
+	Mpeg4Height         int                 `json:"mpeg4_height,omitempty"`
+	Mpeg4Duration       int                 `json:"mpeg4_duration,omitempty"`
+	ThumbUrl            string              `json:"thumb_url"`
+	Title               string              `json:"title,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultVideo struct {
+	ID                  string              `json:"id"`
+	VideoUrl            string              `json:"video_url"`
+	MimeType            string              `json:"mime_type"`
+	ThumbUrl            string              `json:"thumb_url"`
+	Title               string              `json:"title"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	VideoWidth          int                 `json:"video_width,omitempty"`
+	VideoHeight         int                 `json:"video_height,omitempty"`
+	VideoDuration       int                 `json:"video_duration,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultAudio struct {
+	ID                  string              `json:"id"`
+	AudioUrl            string              `json:"audio_url"`
+	Title               string              `json:"title"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	Performer           string              `json:"performer,omitempty"`
+	AudioDuration       int                 `json:"audio_duration,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultVoice struct {
+	ID                  string              `json:"id"`
+	VoiceUrl            string              `json:"voice_url"`
+	Title               string              `json:"title"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	VoiceDuration       int                 `json:"voice_duration,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultDocument struct {
+	ID                  string              `json:"id"`
+	Title               string              `json:"title"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	DocumentUrl         string              `json:"document_url"`
+	MimeType            string              `json:"mime_type"`
+	Description         string              `json:"description,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+	ThumbUrl            string              `json:"thumb_url,omitempty"`
+	ThumbWidth          int                 `json:"thumb_width,omitempty"`
+	ThumbHeight         int                 `json:"thumb_height,omitempty"`
+}
+
+type InlineQueryResultLocation struct {
+	ID                  string              `json:"id"`
+	Latitude            float64             `json:"latitude"`
+	Longitude           float64             `json:"longitude"`
+	Title               string              `json:"title"`
+	LivePeriod          int                 `json:"live_period,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+	ThumbUrl            string              `json:"thumb_url,omitempty"`
+	ThumbWidth          int                 `json:"thumb_width,omitempty"`
+	ThumbHeight         int                 `json:"thumb_height,omitempty"`
+}
+
+type InlineQueryResultVenue struct {
+	ID                  string              `json:"id"`
+	Latitude            float64             `json:"latitude"`
+	Longitude           float64             `json:"longitude"`
+	Title               string              `json:"title"`
+	Address             string              `json:"address"`
+	FoursquareID        string              `json:"foursquare_id,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+	ThumbUrl            string              `json:"thumb_url,omitempty"`
+	ThumbWidth          int                 `json:"thumb_width,omitempty"`
+	ThumbHeight         int                 `json:"thumb_height,omitempty"`
+}
+
+type InlineQueryResultContact struct {
+	ID                  string              `json:"id"`
+	PhoneNumber         string              `json:"phone_number"`
+	FirstName           string              `json:"first_name"`
+	LastName            string              `json:"last_name,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+	ThumbUrl            string              `json:"thumb_url,omitempty"`
+	ThumbWidth          int                 `json:"thumb_width,omitempty"`
+	ThumbHeight         int                 `json:"thumb_height,omitempty"`
+}
+
+type InlineQueryResultGame struct {
+	ID            string  `json:"id"`
+	GameShortName string  `json:"game_short_name"`
+	ReplyMarkup   *Button `json:"reply_markup,omitempty"`
+}
+
+type InlineQueryResultCachedPhoto struct {
+	ID                  string              `json:"id"`
+	PhotoFileID         string              `json:"photo_file_id"`
+	Title               string              `json:"title,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedGif struct {
+	ID                  string              `json:"id"`
+	GifFileID           string              `json:"gif_file_id"`
+	Title               string              `json:"title,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedMpeg4Gif struct {
+	ID                  string              `json:"id"`
+	Mpeg4FileID         string              `json:"mpeg4_file_id"`
+	Title               string              `json:"title,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedSticker struct {
+	ID                  string              `json:"id"`
+	StickerFileID       string              `json:"sticker_file_id"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedDocument struct {
+	ID                  string              `json:"id"`
+	Title               string              `json:"title"`
+	DocumentFileID      string              `json:"document_file_id"`
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedVideo struct {
+	ID                  string              `json:"id"`
+	VideoFileID         string              `json:"video_file_id"`
+	Title               string              `json:"title"`
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedVoice struct {
+	ID                  string              `json:"id"`
+	VoiceFileID         string              `json:"voice_file_id"`
+	Title               string              `json:"title"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+type InlineQueryResultCachedAudio struct {
+	ID                  string              `json:"id"`
+	AudioFileID         string              `json:"audio_file_id"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ReplyMarkup         *Button             `json:"reply_markup,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func (InlineQueryResultArticle) ResultType() string        { return "article" }
+func (InlineQueryResultPhoto) ResultType() string          { return "photo" }
+func (InlineQueryResultGif) ResultType() string            { return "gif" }
+func (InlineQueryResultMpeg4Gif) ResultType() string       { return "mpeg4_gif" }
+func (InlineQueryResultVideo) ResultType() string          { return "video" }
+func (InlineQueryResultAudio) ResultType() string          { return "audio" }
+func (InlineQueryResultVoice) ResultType() string          { return "voice" }
+func (InlineQueryResultDocument) ResultType() string       { return "document" }
+func (InlineQueryResultLocation) ResultType() string       { return "location" }
+func (InlineQueryResultVenue) ResultType() string          { return "venue" }
+func (InlineQueryResultContact) ResultType() string        { return "contact" }
+func (InlineQueryResultGame) ResultType() string           { return "game" }
+func (InlineQueryResultCachedPhoto) ResultType() string    { return "photo" }
+func (InlineQueryResultCachedGif) ResultType() string      { return "gif" }
+func (InlineQueryResultCachedMpeg4Gif) ResultType() string { return "mpeg4_gif" }
+func (InlineQueryResultCachedSticker) ResultType() string  { return "sticker" }
+func (InlineQueryResultCachedDocument) ResultType() string { return "document" }
+func (InlineQueryResultCachedVideo) ResultType() string    { return "video" }
+func (InlineQueryResultCachedVoice) ResultType() string    { return "voice" }
+func (InlineQueryResultCachedAudio) ResultType() string    { return "audio" }
+
+// InlineResults marshals every result with its "type" field in front.
+type InlineResults []InlineQueryResult
+
+func (rs InlineResults) MarshalJSON() ([]byte, error) {
+	out := make([]json.RawMessage, 0, len(rs))
+	for _, r := range rs {
+		b, err := marshalTyped(r.ResultType(), r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return json.Marshal(out)
+}
+
+// InlineAnswer is what an InlineAnswerHandler returns for a query.
+type InlineAnswer struct {
+	Results           []InlineQueryResult
+	CacheTime         int
+	IsPersonal        bool
+	NextOffset        string
+	SwitchPmText      string
+	SwitchPmParameter string
+
+	// raw replaces Results with already marshaled ones, as kept by InlinePager
+	raw json.RawMessage
+}
+
+type InlineAnswerHandler func(obj *Action, query *InlineQuery) *InlineAnswer
+
+// AnswerInline adapts h to an InlineQueryHandler that sends h's answer.
+// A nil answer leaves the query unanswered.
+func AnswerInline(h InlineAnswerHandler) InlineQueryHandler {
+	return func(obj *Action, query *InlineQuery) {
+		if answer := h(obj, query); answer != nil {
+			Dbg(obj.answerInlineQuery(query, answer))
+		}
+	}
+}
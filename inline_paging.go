@@ -0,0 +1,206 @@
+// inline_paging.go
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const (
+	inlineCacheBucket = "InlineCache"
+	inlinePagesBucket = "pages"
+	inlineExpiry      = "expiry"
+	inlinePagerName   = "default"
+	inlinePageLimit   = 50
+	inlineCacheTTL    = 5 * time.Minute
+)
+
+// InlineSource returns every result for a query, InlinePager cuts it into pages.
+type InlineSource func(obj *Action, query *InlineQuery) []InlineQueryResult
+
+// InlinePager answers inline queries page by page through InlineQuery.Offset
+// and next_offset. The pages of a query are computed once and kept in bolt
+// for CacheTTL, keyed by query, offset and, when IsPersonal, the user. Each
+// pager caches in a bucket of its own, Name tells them apart and must be
+// set when more than one pager is used.
+type InlinePager struct {
+	Name       string
+	Source     InlineSource
+	PerPage    int
+	CacheTime  int
+	IsPersonal bool
+	CacheTTL   time.Duration
+}
+
+type inlinePage struct {
+	Stored     int64           `json:"stored"`
+	NextOffset string          `json:"next_offset"`
+	Results    json.RawMessage `json:"results"`
+}
+
+func (p *InlinePager) bucket() []byte {
+	if p.Name == "" {
+		return []byte(inlinePagerName)
+	}
+	return []byte(p.Name)
+}
+
+func (p *InlinePager) cacheKey(query *InlineQuery, offset string) []byte {
+	key := query.Query + "\x00" + offset
+	if p.IsPersonal {
+		key += "\x00" + strconv.Itoa(query.From.ID)
+	}
+	return []byte(key)
+}
+
+// answer is an InlineAnswerHandler, register it with AnswerInline(pager.answer).
+func (p *InlinePager) answer(obj *Action, query *InlineQuery) *InlineAnswer {
+	perPage := p.PerPage
+	if perPage <= 0 || perPage > inlinePageLimit {
+		perPage = inlinePageLimit
+	}
+	ttl := p.CacheTTL
+	if ttl == 0 {
+		ttl = inlineCacheTTL
+	}
+
+	ans := &InlineAnswer{CacheTime: p.CacheTime, IsPersonal: p.IsPersonal}
+	if page := obj.loadInlinePage(p, p.cacheKey(query, query.Offset), ttl); page != nil {
+		ans.NextOffset = page.NextOffset
+		ans.raw = page.Results
+		return ans
+	}
+
+	results := p.Source(obj, query)
+	pages := make(map[string]*inlinePage)
+	for from := 0; from == 0 || from < len(results); from += perPage {
+		to := from + perPage
+		if to > len(results) {
+			to = len(results)
+		}
+		page := &inlinePage{Stored: time.Now().Unix()}
+		if to < len(results) {
+			page.NextOffset = strconv.Itoa(to)
+		}
+		raw, err := json.Marshal(InlineResults(results[from:to]))
+		if err != nil {
+			Dbg(err)
+			return nil
+		}
+		page.Results = raw
+		offset := ""
+		if from > 0 {
+			offset = strconv.Itoa(from)
+		}
+		pages[offset] = page
+	}
+	Dbg(obj.storeInlinePages(p, query, pages, ttl))
+
+	page, ok := pages[query.Offset]
+	if !ok {
+		// an offset that is not a page boundary, answer with nothing more
+		page = &inlinePage{Results: json.RawMessage("[]")}
+	}
+	ans.NextOffset = page.NextOffset
+	ans.raw = page.Results
+	return ans
+}
+
+func (obj *Action) loadInlinePage(p *InlinePager, key []byte, ttl time.Duration) *inlinePage {
+	var page *inlinePage
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(inlineCacheBucket))
+		if b == nil {
+			return nil
+		}
+		if b = b.Bucket(p.bucket()); b == nil {
+			return nil
+		}
+		v := b.Bucket([]byte(inlinePagesBucket)).Get(key)
+		if v == nil {
+			return nil
+		}
+		cached := new(inlinePage)
+		if err := json.Unmarshal(v, cached); err != nil {
+			return err
+		}
+		if time.Since(time.Unix(cached.Stored, 0)) < ttl {
+			page = cached
+		}
+		return nil
+	})
+	return page
+}
+
+// inlineExpiryKey orders the expiry index by the time a page was stored.
+func inlineExpiryKey(stored int64, key []byte) []byte {
+	k := make([]byte, 8, 8+len(key))
+	binary.BigEndian.PutUint64(k, uint64(stored))
+	return append(k, key...)
+}
+
+// storeInlinePages saves the pages of one query in the bucket of p and
+// drops the pages of p that expired, found through its expiry index
+// without reading the pages themselves.
+func (obj *Action) storeInlinePages(p *InlinePager, query *InlineQuery, pages map[string]*inlinePage, ttl time.Duration) error {
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(inlineCacheBucket))
+		if err != nil {
+			return err
+		}
+		pager, err := root.CreateBucketIfNotExists(p.bucket())
+		if err != nil {
+			return err
+		}
+		b, err := pager.CreateBucketIfNotExists([]byte(inlinePagesBucket))
+		if err != nil {
+			return err
+		}
+		expiry, err := pager.CreateBucketIfNotExists([]byte(inlineExpiry))
+		if err != nil {
+			return err
+		}
+
+		var expired [][]byte
+		cutoff := time.Now().Add(-ttl).Unix()
+		c := expiry.Cursor()
+		for k, _ := c.First(); k != nil && int64(binary.BigEndian.Uint64(k[:8])) <= cutoff; k, _ = c.Next() {
+			expired = append(expired, append([]byte{}, k...))
+		}
+		for _, k := range expired {
+			if err := expiry.Delete(k); err != nil {
+				return err
+			}
+			// a page stored again later has a newer index entry, keep it
+			if v := b.Get(k[8:]); v != nil {
+				page := new(inlinePage)
+				if json.Unmarshal(v, page) == nil && page.Stored > cutoff {
+					continue
+				}
+			}
+			if err := b.Delete(k[8:]); err != nil {
+				return err
+			}
+		}
+
+		for offset, page := range pages {
+			v, err := json.Marshal(page)
+			if err != nil {
+				return err
+			}
+			key := p.cacheKey(query, offset)
+			if err := b.Put(key, v); err != nil {
+				return err
+			}
+			if err := expiry.Put(inlineExpiryKey(page.Stored, key), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+// keyboard.go
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+const maxCallbackData = 64
+
+// CallbackGame is sent as an empty object, it has no fields yet.
+type CallbackGame struct{}
+
+// KeyboardBuilder assembles an inline keyboard row by row. The first error
+// stops the builder and is returned by Build.
+type KeyboardBuilder struct {
+	rows    [][]Button_
+	columns int
+	err     error
+}
+
+func NewKeyboard() *KeyboardBuilder {
+	return &KeyboardBuilder{}
+}
+
+// Columns wraps rows automatically after n buttons, 0 disables wrapping.
+func (k *KeyboardBuilder) Columns(n int) *KeyboardBuilder {
+	k.columns = n
+	return k
+}
+
+// Row starts a new row, empty rows are dropped by Build.
+func (k *KeyboardBuilder) Row() *KeyboardBuilder {
+	k.rows = append(k.rows, []Button_{})
+	return k
+}
+
+func (k *KeyboardBuilder) add(but Button_) *KeyboardBuilder {
+	if k.err != nil {
+		return k
+	}
+	if len(but.CallbackData) > maxCallbackData {
+		k.err = fmt.Errorf("callback_data of %q is %d bytes, limit is %d", but.Text, len(but.CallbackData), maxCallbackData)
+		return k
+	}
+	if len(k.rows) == 0 || (k.columns > 0 && len(k.rows[len(k.rows)-1]) >= k.columns) {
+		k.Row()
+	}
+	last := len(k.rows) - 1
+	k.rows[last] = append(k.rows[last], but)
+	return k
+}
+
+func (k *KeyboardBuilder) Callback(text, data string) *KeyboardBuilder {
+	return k.add(Button_{Text: text, CallbackData: data})
+}
+
+func (k *KeyboardBuilder) Url(text, url string) *KeyboardBuilder {
+	return k.add(Button_{Text: text, Url: url})
+}
+
+// SwitchInline offers to pick a chat and insert the bot's username and query.
+func (k *KeyboardBuilder) SwitchInline(text, query string) *KeyboardBuilder {
+	return k.add(Button_{Text: text, SwitchInlineQuery: &query})
+}
+
+// SwitchInlineCurrentChat inserts the bot's username and query in the current chat.
+func (k *KeyboardBuilder) SwitchInlineCurrentChat(text, query string) *KeyboardBuilder {
+	return k.add(Button_{Text: text, SwitchInlineQueryCurrentChat: &query})
+}
+
+// Game must be the first button of the first row.
+func (k *KeyboardBuilder) Game(text string) *KeyboardBuilder {
+	if !k.first() {
+		k.err = errors.New("callback_game button must be the first button in the first row")
+		return k
+	}
+	return k.add(Button_{Text: text, CallbackGame: &CallbackGame{}})
+}
+
+// Pay must be the first button of the first row.
+func (k *KeyboardBuilder) Pay(text string) *KeyboardBuilder {
+	if !k.first() {
+		k.err = errors.New("pay button must be the first button in the first row")
+		return k
+	}
+	return k.add(Button_{Text: text, Pay: true})
+}
+
+func (k *KeyboardBuilder) first() bool {
+	for _, row := range k.rows {
+		if len(row) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Page adds the buttons of page (counted from 0) with perPage buttons per
+// page, followed by a navigation row whose callback data is nav(page-1) and
+// nav(page+1). nav may be nil only when all buttons fit on one page.
+func (k *KeyboardBuilder) Page(buttons []Button_, page, perPage int, nav func(page int) string) *KeyboardBuilder {
+	if k.err != nil {
+		return k
+	}
+	if perPage <= 0 {
+		perPage = len(buttons)
+	}
+	if page < 0 {
+		k.err = fmt.Errorf("page %d is negative", page)
+		return k
+	}
+	if nav == nil && perPage < len(buttons) {
+		k.err = errors.New("paging over more than one page needs nav")
+		return k
+	}
+	from := page * perPage
+	if from > len(buttons) {
+		from = len(buttons)
+	}
+	to := from + perPage
+	if to > len(buttons) {
+		to = len(buttons)
+	}
+	k.Row()
+	for _, but := range buttons[from:to] {
+		k.add(but)
+	}
+	if from == 0 && to == len(buttons) {
+		return k
+	}
+	k.Row()
+	columns := k.columns
+	k.columns = 0
+	if page > 0 {
+		k.Callback("«", nav(page-1))
+	}
+	if to < len(buttons) {
+		k.Callback("»", nav(page+1))
+	}
+	k.columns = columns
+	return k
+}
+
+func (k *KeyboardBuilder) Build() (Button, error) {
+	but := Button{}
+	if k.err != nil {
+		return but, k.err
+	}
+	for _, row := range k.rows {
+		if len(row) > 0 {
+			but.InlineKeyboard = append(but.InlineKeyboard, row)
+		}
+	}
+	return but, nil
+}
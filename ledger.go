@@ -0,0 +1,328 @@
+// ledger.go
+package main
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const (
+	paymentsBucket    = "Payments"
+	preCheckoutBucket = "PreCheckout"
+)
+
+// PaymentRecord is a successful payment as kept in the ledger.
+type PaymentRecord struct {
+	TelegramPaymentChargeID string    `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeID string    `json:"provider_payment_charge_id"`
+	InvoicePayload          string    `json:"invoice_payload"`
+	ShippingOptionID        string    `json:"shipping_option_id"`
+	Currency                string    `json:"currency"`
+	TotalAmount             int       `json:"total_amount"`
+	OrderInfo               OrderInfo `json:"order_info"`
+	UserID                  int       `json:"user_id"`
+	ChatID                  int       `json:"chat_id"`
+	Date                    int       `json:"date"`
+}
+
+// PreCheckoutRecord is a pre-checkout query the bot approved.
+type PreCheckoutRecord struct {
+	ID             string `json:"id"`
+	UserID         int    `json:"user_id"`
+	InvoicePayload string `json:"invoice_payload"`
+	Currency       string `json:"currency"`
+	TotalAmount    int    `json:"total_amount"`
+	Date           int    `json:"date"`
+	ChargeID       string `json:"charge_id"`
+}
+
+// Completed reports whether a successful payment was matched to the checkout.
+func (rec *PreCheckoutRecord) Completed() bool {
+	return rec.ChargeID != ""
+}
+
+func init() {
+	registerChatMigration(migratePayments)
+}
+
+// TrackPayments saves successful payments into the ledger before handing the
+// message to h, which may be nil.
+func TrackPayments(h MessageHandler) MessageHandler {
+	return func(obj *Action, msg *Message) {
+		if msg.SuccessfulPayment.TelegramPaymentChargeID != "" {
+			Dbg(obj.recordPayment(msg))
+		}
+		if h != nil {
+			h(obj, msg)
+		}
+	}
+}
+
+// ledgerKey orders payments by date, so date ranges are cursor seeks.
+func ledgerKey(date int, chargeID string) []byte {
+	key := make([]byte, 8, 8+len(chargeID))
+	binary.BigEndian.PutUint64(key, uint64(date))
+	return append(key, chargeID...)
+}
+
+func (obj *Action) recordPayment(msg *Message) error {
+	pay := msg.SuccessfulPayment
+	rec := PaymentRecord{
+		TelegramPaymentChargeID: pay.TelegramPaymentChargeID,
+		ProviderPaymentChargeID: pay.ProviderPaymentChargeID,
+		InvoicePayload:          pay.InvoicePayload,
+		ShippingOptionID:        pay.ShippingOptionID,
+		Currency:                pay.Currency,
+		TotalAmount:             pay.TotalAmount,
+		OrderInfo:               pay.OrderInfo,
+		UserID:                  msg.From.ID,
+		ChatID:                  msg.Chat.ID,
+		Date:                    msg.Date,
+	}
+	v, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(paymentsBucket))
+		if err != nil {
+			return err
+		}
+		key := ledgerKey(rec.Date, rec.TelegramPaymentChargeID)
+		// a payment delivered again must not complete a second checkout
+		if b.Get(key) != nil {
+			return nil
+		}
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+		return completeCheckout(tx, &rec)
+	})
+}
+
+func migratePayments(tx *bolt.Tx, from, to int) error {
+	b := tx.Bucket([]byte(paymentsBucket))
+	if b == nil {
+		return nil
+	}
+	moved := make(map[string][]byte)
+	err := b.ForEach(func(k, v []byte) error {
+		rec := PaymentRecord{}
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+		if rec.ChatID != from {
+			return nil
+		}
+		rec.ChatID = to
+		nv, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		moved[string(k)] = nv
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, v := range moved {
+		if err := b.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeCheckout marks the oldest open pre-checkout of the same user,
+// payload and amount as paid, unless a checkout already carries the charge.
+func completeCheckout(tx *bolt.Tx, rec *PaymentRecord) error {
+	b := tx.Bucket([]byte(preCheckoutBucket))
+	if b == nil {
+		return nil
+	}
+	var match *PreCheckoutRecord
+	done := false
+	b.ForEach(func(k, v []byte) error {
+		pre := new(PreCheckoutRecord)
+		if json.Unmarshal(v, pre) != nil {
+			return nil
+		}
+		if pre.ChargeID == rec.TelegramPaymentChargeID {
+			done = true
+		}
+		if pre.Completed() {
+			return nil
+		}
+		if pre.UserID == rec.UserID && pre.InvoicePayload == rec.InvoicePayload &&
+			pre.Currency == rec.Currency && pre.TotalAmount == rec.TotalAmount &&
+			(match == nil || pre.Date < match.Date) {
+			match = pre
+		}
+		return nil
+	})
+	if match == nil || done {
+		return nil
+	}
+	match.ChargeID = rec.TelegramPaymentChargeID
+	v, err := json.Marshal(match)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(match.ID), v)
+}
+
+func (obj *Action) recordPreCheckout(query *PreCheckoutQuery) error {
+	rec := PreCheckoutRecord{
+		ID:             query.ID,
+		UserID:         query.From.ID,
+		InvoicePayload: query.InvoicePayload,
+		Currency:       query.Currency,
+		TotalAmount:    query.TotalAmount,
+		Date:           int(time.Now().Unix()),
+	}
+	v, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(preCheckoutBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(rec.ID), v)
+	})
+}
+
+// paymentsBetween lists the payments made in [from, to).
+func (obj *Action) paymentsBetween(from, to time.Time) ([]PaymentRecord, error) {
+	var ret []PaymentRecord
+	err := obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(paymentsBucket))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		end := ledgerKey(int(to.Unix()), "")
+		for k, v := c.Seek(ledgerKey(int(from.Unix()), "")); k != nil && string(k) < string(end); k, v = c.Next() {
+			rec := PaymentRecord{}
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			ret = append(ret, rec)
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// incompleteCheckouts lists the approved pre-checkouts older than grace that
+// never got a successful payment.
+func (obj *Action) incompleteCheckouts(grace time.Duration) ([]PreCheckoutRecord, error) {
+	var ret []PreCheckoutRecord
+	before := int(time.Now().Add(-grace).Unix())
+	err := obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(preCheckoutBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			rec := PreCheckoutRecord{}
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			if !rec.Completed() && rec.Date < before {
+				ret = append(ret, rec)
+			}
+			return nil
+		})
+	})
+	return ret, err
+}
+
+func WritePaymentsCSV(w io.Writer, recs []PaymentRecord) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"date", "telegram_payment_charge_id", "provider_payment_charge_id",
+		"invoice_payload", "currency", "total_amount", "user_id", "name", "phone_number", "email",
+		"country_code", "state", "city", "street_line1", "street_line2", "post_code"})
+	for _, rec := range recs {
+		addr := rec.OrderInfo.ShippingAddress
+		out.Write([]string{
+			time.Unix(int64(rec.Date), 0).UTC().Format(time.RFC3339),
+			rec.TelegramPaymentChargeID,
+			rec.ProviderPaymentChargeID,
+			rec.InvoicePayload,
+			rec.Currency,
+			FormatAmount(rec.Currency, rec.TotalAmount),
+			strconv.Itoa(rec.UserID),
+			rec.OrderInfo.Name,
+			rec.OrderInfo.PhoneNumber,
+			rec.OrderInfo.Email,
+			addr.CountryCode, addr.Stat, addr.City, addr.StreetLine1, addr.StreetLine2, addr.PostCode,
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func WriteCheckoutsCSV(w io.Writer, recs []PreCheckoutRecord) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"date", "pre_checkout_query_id", "user_id", "invoice_payload", "currency", "total_amount"})
+	for _, rec := range recs {
+		out.Write([]string{
+			time.Unix(int64(rec.Date), 0).UTC().Format(time.RFC3339),
+			rec.ID,
+			strconv.Itoa(rec.UserID),
+			rec.InvoicePayload,
+			rec.Currency,
+			FormatAmount(rec.Currency, rec.TotalAmount),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+const (
+	ledgerDate         = "2006-01-02"
+	incompleteCheckout = time.Hour
+)
+
+// exportLedger writes either "payments" made between the dates from and to
+// (inclusive, empty for open ends) or "incomplete" checkouts as CSV to w.
+func (obj *Action) exportLedger(w io.Writer, what, from, to string) error {
+	switch what {
+	case "payments":
+		start, end := time.Unix(0, 0), time.Now().Add(24*time.Hour)
+		var err error
+		if from != "" {
+			if start, err = time.Parse(ledgerDate, from); err != nil {
+				return err
+			}
+		}
+		if to != "" {
+			if end, err = time.Parse(ledgerDate, to); err != nil {
+				return err
+			}
+			end = end.Add(24 * time.Hour)
+		}
+		recs, err := obj.paymentsBetween(start, end)
+		if err != nil {
+			return err
+		}
+		return WritePaymentsCSV(w, recs)
+	case "incomplete":
+		recs, err := obj.incompleteCheckouts(incompleteCheckout)
+		if err != nil {
+			return err
+		}
+		return WriteCheckoutsCSV(w, recs)
+	}
+	return fmt.Errorf("unknown export %q, use payments or incomplete", what)
+}
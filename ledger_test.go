@@ -0,0 +1,42 @@
+// ledger_test.go
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordPaymentTwice(t *testing.T) {
+	obj := newTestAction(t)
+	for _, id := range []string{"pre1", "pre2"} {
+		query := &PreCheckoutQuery{ID: id, From: User{ID: 7}, Currency: "EUR", TotalAmount: 500, InvoicePayload: "order"}
+		if err := obj.recordPreCheckout(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msg := &Message{From: User{ID: 7}, Chat: Chat{ID: 7}, Date: 1000}
+	msg.SuccessfulPayment = SuccessfulPayment{
+		Currency:                "EUR",
+		TotalAmount:             500,
+		InvoicePayload:          "order",
+		TelegramPaymentChargeID: "charge1",
+	}
+	// the second time is the same message delivered again
+	for i := 0; i < 2; i++ {
+		if err := obj.recordPayment(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recs, err := obj.paymentsBetween(time.Unix(0, 0), time.Unix(2000, 0))
+	if err != nil || len(recs) != 1 {
+		t.Errorf("payments = %+v, %v, want one", recs, err)
+	}
+	open, err := obj.incompleteCheckouts(-time.Hour)
+	if err != nil || len(open) != 1 {
+		t.Fatalf("incomplete checkouts = %+v, %v, want one", open, err)
+	}
+	if open[0].ChargeID != "" {
+		t.Errorf("open checkout carries charge %q", open[0].ChargeID)
+	}
+}
@@ -0,0 +1,125 @@
+// locations.go
+package main
+
+type PayloadSendLocation struct {
+	ChatID    int     `json:"chat_id"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// LivePeriod in seconds, 60 to 86400, makes the location live.
+	LivePeriod          int         `json:"live_period,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int         `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+type PayloadSendVenue struct {
+	ChatID              int         `json:"chat_id"`
+	Latitude            float64     `json:"latitude"`
+	Longitude           float64     `json:"longitude"`
+	Title               string      `json:"title"`
+	Address             string      `json:"address"`
+	FoursquareID        string      `json:"foursquare_id,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int         `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+type PayloadSendContact struct {
+	ChatID              int         `json:"chat_id"`
+	PhoneNumber         string      `json:"phone_number"`
+	FirstName           string      `json:"first_name"`
+	LastName            string      `json:"last_name,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int         `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+func (obj *Action) sendLocation(data PayloadSendLocation) (*SendMessageReturn, error) {
+	ret := new(SendMessageReturn)
+	err := obj.call("sendLocation", data, ret)
+	return ret, err
+}
+
+// editMessageLiveLocation moves a live location until its live_period ends
+// or it is stopped.
+func (obj *Action) editMessageLiveLocation(ref MessageRef, latitude, longitude float64, markup *Button) (*Message, error) {
+	type Payload struct {
+		MessageRef
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		ReplyMarkup *Button `json:"reply_markup,omitempty"`
+	}
+	return obj.edit("editMessageLiveLocation", ref, Payload{ref, latitude, longitude, markup})
+}
+
+func (obj *Action) stopMessageLiveLocation(ref MessageRef, markup *Button) (*Message, error) {
+	type Payload struct {
+		MessageRef
+		ReplyMarkup *Button `json:"reply_markup,omitempty"`
+	}
+	return obj.edit("stopMessageLiveLocation", ref, Payload{ref, markup})
+}
+
+func (obj *Action) sendVenue(data PayloadSendVenue) (*SendMessageReturn, error) {
+	ret := new(SendMessageReturn)
+	err := obj.call("sendVenue", data, ret)
+	return ret, err
+}
+
+func (obj *Action) sendContact(data PayloadSendContact) (*SendMessageReturn, error) {
+	ret := new(SendMessageReturn)
+	err := obj.call("sendContact", data, ret)
+	return ret, err
+}
+
+// RequestLocationKeyboard is a one-time keyboard with a single button that
+// shares the user's location, the answer arrives at Shares.OnLocation.
+func RequestLocationKeyboard(text string) ReplyKeyboardMarkup {
+	return ReplyKeyboardMarkup{
+		Keyboard:        [][]KeyboardButton{{{Text: text, RequestLocation: true}}},
+		ResizeKeyboard:  true,
+		OneTimeKeyboard: true,
+	}
+}
+
+// RequestContactKeyboard is like RequestLocationKeyboard for the user's
+// phone number, the answer arrives at Shares.OnContact.
+func RequestContactKeyboard(text string) ReplyKeyboardMarkup {
+	return ReplyKeyboardMarkup{
+		Keyboard:        [][]KeyboardButton{{{Text: text, RequestContact: true}}},
+		ResizeKeyboard:  true,
+		OneTimeKeyboard: true,
+	}
+}
+
+// LocationHandler also gets updates of live locations, which arrive as
+// edited messages with EditDate set.
+type LocationHandler func(obj *Action, msg *Message, location *Location)
+type VenueHandler func(obj *Action, msg *Message, venue *Venue)
+type ContactHandler func(obj *Action, msg *Message, contact *Contact)
+
+// Shares holds the handlers for locations, venues and contacts users send.
+type Shares struct {
+	OnLocation LocationHandler
+	OnVenue    VenueHandler
+	OnContact  ContactHandler
+}
+
+// Route wraps next, messages without a handled share are passed to it. A
+// venue carries a location too, it goes to OnVenue when that is set.
+func (s *Shares) Route(next MessageHandler) MessageHandler {
+	return func(obj *Action, msg *Message) {
+		switch {
+		case msg.Venue != nil && s.OnVenue != nil:
+			s.OnVenue(obj, msg, msg.Venue)
+		case msg.Location != nil && s.OnLocation != nil:
+			s.OnLocation(obj, msg, msg.Location)
+		case msg.Contact != nil && s.OnContact != nil:
+			s.OnContact(obj, msg, msg.Contact)
+		default:
+			if next != nil {
+				next(obj, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// markup.go
+package main
+
+import "encoding/json"
+
+// ReplyMarkup is one of Button (inline keyboard), ReplyKeyboardMarkup,
+// ReplyKeyboardRemove or ForceReply. A nil ReplyMarkup sends no reply_markup.
+type ReplyMarkup interface {
+	replyMarkup()
+}
+
+type KeyboardButton struct {
+	Text            string `json:"text"`
+	RequestContact  bool   `json:"request_contact,omitempty"`
+	RequestLocation bool   `json:"request_location,omitempty"`
+}
+
+type ReplyKeyboardMarkup struct {
+	Keyboard        [][]KeyboardButton `json:"keyboard"`
+	ResizeKeyboard  bool               `json:"resize_keyboard,omitempty"`
+	OneTimeKeyboard bool               `json:"one_time_keyboard,omitempty"`
+	Selective       bool               `json:"selective,omitempty"`
+}
+
+// ReplyKeyboardRemove always marshals remove_keyboard as true.
+type ReplyKeyboardRemove struct {
+	Selective bool `json:"selective,omitempty"`
+}
+
+// ForceReply always marshals force_reply as true.
+type ForceReply struct {
+	Selective bool `json:"selective,omitempty"`
+}
+
+func (Button) replyMarkup()              {}
+func (ReplyKeyboardMarkup) replyMarkup() {}
+func (ReplyKeyboardRemove) replyMarkup() {}
+func (ForceReply) replyMarkup()          {}
+
+func (m ReplyKeyboardRemove) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		RemoveKeyboard bool `json:"remove_keyboard"`
+		Selective      bool `json:"selective,omitempty"`
+	}{true, m.Selective})
+}
+
+func (m ForceReply) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ForceReply bool `json:"force_reply"`
+		Selective  bool `json:"selective,omitempty"`
+	}{true, m.Selective})
+}
+
+// NewReplyKeyboard builds a custom keyboard from rows of button texts.
+func NewReplyKeyboard(rows ...[]string) ReplyKeyboardMarkup {
+	markup := ReplyKeyboardMarkup{ResizeKeyboard: true}
+	for _, row := range rows {
+		buts := []KeyboardButton{}
+		for _, text := range row {
+			buts = append(buts, KeyboardButton{Text: text})
+		}
+		markup.Keyboard = append(markup.Keyboard, buts)
+	}
+	return markup
+}
@@ -0,0 +1,175 @@
+// media.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// InputFile is a file to send: a local Path or a Reader are uploaded, a Url
+// is fetched by Telegram and a FileID reuses a file already on its servers.
+type InputFile struct {
+	Path   string
+	Reader io.Reader
+	// Name is the file name sent along with Reader.
+	Name   string
+	Url    string
+	FileID string
+}
+
+func FilePath(path string) InputFile                { return InputFile{Path: path} }
+func FileReader(name string, r io.Reader) InputFile { return InputFile{Name: name, Reader: r} }
+func FileUrl(url string) InputFile                  { return InputFile{Url: url} }
+func FileID(id string) InputFile                    { return InputFile{FileID: id} }
+
+func (f InputFile) upload() bool {
+	return f.Path != "" || f.Reader != nil
+}
+
+// value is the form value of a file that is not uploaded.
+func (f InputFile) value() string {
+	if f.FileID != "" {
+		return f.FileID
+	}
+	return f.Url
+}
+
+func (f InputFile) open() (io.Reader, string, error) {
+	if f.Reader != nil {
+		name := f.Name
+		if name == "" {
+			name = "file"
+		}
+		return f.Reader, name, nil
+	}
+	r, err := os.Open(f.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, filepath.Base(f.Path), nil
+}
+
+// MediaOptions are the optional parameters of the send methods, each method
+// uses the ones the Bot API defines for it.
+type MediaOptions struct {
+	Caption             string
+	ParseMode           string
+	Duration            int
+	Width               int
+	Height              int
+	Length              int
+	Performer           string
+	Title               string
+	SupportsStreaming   bool
+	Thumb               *InputFile
+	DisableNotification bool
+	ReplyToMessageID    int
+	ReplyMarkup         ReplyMarkup
+}
+
+func (opts *MediaOptions) params(chatID int) (map[string]string, error) {
+	params := map[string]string{"chat_id": strconv.Itoa(chatID)}
+	set := func(k, v string) {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	setInt := func(k string, v int) {
+		if v != 0 {
+			params[k] = strconv.Itoa(v)
+		}
+	}
+	setBool := func(k string, v bool) {
+		if v {
+			params[k] = "true"
+		}
+	}
+	set("caption", opts.Caption)
+	set("parse_mode", opts.ParseMode)
+	setInt("duration", opts.Duration)
+	setInt("width", opts.Width)
+	setInt("height", opts.Height)
+	setInt("length", opts.Length)
+	set("performer", opts.Performer)
+	set("title", opts.Title)
+	setBool("supports_streaming", opts.SupportsStreaming)
+	setBool("disable_notification", opts.DisableNotification)
+	setInt("reply_to_message_id", opts.ReplyToMessageID)
+	if opts.ReplyMarkup != nil {
+		markup, err := json.Marshal(opts.ReplyMarkup)
+		if err != nil {
+			return nil, err
+		}
+		params["reply_markup"] = string(markup)
+	}
+	return params, nil
+}
+
+// sendMedia sends file as the field of method, opts may be nil.
+func (obj *Action) sendMedia(method, field string, chatID int, file InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	if !file.upload() && file.value() == "" {
+		return nil, errors.New(method + ": empty InputFile")
+	}
+	if opts == nil {
+		opts = &MediaOptions{}
+	}
+	params, err := opts.params(chatID)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]InputFile{field: file}
+	if opts.Thumb != nil {
+		// thumbnails are only accepted as uploads attached by name
+		if !opts.Thumb.upload() {
+			return nil, errors.New(method + ": thumb must be an upload, not a file_id or url")
+		}
+		files["thumb_file"] = *opts.Thumb
+		params["thumb"] = "attach://thumb_file"
+	}
+	ret := new(SendMessageReturn)
+	err = obj.callUpload(method, params, files, ret)
+	if err == nil {
+		obj.Msg = ret
+	}
+	return ret, err
+}
+
+func (obj *Action) sendPhoto(chatID int, photo InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendPhoto", "photo", chatID, photo, opts)
+}
+
+func (obj *Action) sendDocument(chatID int, document InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendDocument", "document", chatID, document, opts)
+}
+
+func (obj *Action) sendAudio(chatID int, audio InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendAudio", "audio", chatID, audio, opts)
+}
+
+func (obj *Action) sendVideo(chatID int, video InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendVideo", "video", chatID, video, opts)
+}
+
+func (obj *Action) sendVoice(chatID int, voice InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendVoice", "voice", chatID, voice, opts)
+}
+
+// sendVideoNote cannot take a URL, Telegram only accepts uploads and file_ids.
+func (obj *Action) sendVideoNote(chatID int, videoNote InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	if videoNote.Url != "" {
+		return nil, errors.New("sendVideoNote: sending video notes by URL is not supported")
+	}
+	return obj.sendMedia("sendVideoNote", "video_note", chatID, videoNote, opts)
+}
+
+func (obj *Action) sendAnimation(chatID int, animation InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendAnimation", "animation", chatID, animation, opts)
+}
+
+func (obj *Action) sendSticker(chatID int, sticker InputFile, opts *MediaOptions) (*SendMessageReturn, error) {
+	return obj.sendMedia("sendSticker", "sticker", chatID, sticker, opts)
+}
@@ -0,0 +1,186 @@
+// payments.go
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	. "github.com/ulvham/helper"
+)
+
+// currencyExponents lists the currencies whose minor unit is not 1/100 of
+// the major one, see https://core.telegram.org/bots/payments/currencies.json
+var currencyExponents = map[string]int{
+	"CLP": 0, "ISK": 0, "JPY": 0, "KRW": 0, "PYG": 0, "UGX": 0, "VND": 0,
+}
+
+// MinorUnits returns the number of decimal digits of currency.
+func MinorUnits(currency string) int {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// ParseAmount converts a decimal amount such as "12.34" into minor units of
+// currency, the integer Telegram expects in prices and total_amount.
+func ParseAmount(currency, amount string) (int, error) {
+	exp := MinorUnits(currency)
+	whole, frac := amount, ""
+	if i := strings.Index(amount, "."); i >= 0 {
+		whole, frac = amount[:i], amount[i+1:]
+	}
+	if len(frac) > exp {
+		return 0, fmt.Errorf("amount %s has more than %d decimals for %s", amount, exp, currency)
+	}
+	if strings.TrimLeft(whole, "+-")+frac == "" {
+		return 0, fmt.Errorf("invalid amount %q: no digits", amount)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+	n, err := strconv.Atoi(whole + frac)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %v", amount, err)
+	}
+	return n, nil
+}
+
+// FormatAmount is the inverse of ParseAmount.
+func FormatAmount(currency string, minor int) string {
+	exp := MinorUnits(currency)
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	s := strconv.Itoa(minor)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// LabeledPrice is one line of an invoice, Amount is in minor units.
+type LabeledPrice struct {
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
+}
+
+func TotalOf(prices []LabeledPrice) int {
+	total := 0
+	for _, p := range prices {
+		total += p.Amount
+	}
+	return total
+}
+
+type ShippingOption struct {
+	ID     string         `json:"id"`
+	Title  string         `json:"title"`
+	Prices []LabeledPrice `json:"prices"`
+}
+
+type PayloadSendInvoice struct {
+	ChatID                    int            `json:"chat_id"`
+	Title                     string         `json:"title"`
+	Description               string         `json:"description"`
+	Payload                   string         `json:"payload"`
+	ProviderToken             string         `json:"provider_token"`
+	StartParameter            string         `json:"start_parameter"`
+	Currency                  string         `json:"currency"`
+	Prices                    []LabeledPrice `json:"prices"`
+	ProviderData              string         `json:"provider_data,omitempty"`
+	PhotoUrl                  string         `json:"photo_url,omitempty"`
+	PhotoSize                 int            `json:"photo_size,omitempty"`
+	PhotoWidth                int            `json:"photo_width,omitempty"`
+	PhotoHeight               int            `json:"photo_height,omitempty"`
+	NeedName                  bool           `json:"need_name,omitempty"`
+	NeedPhoneNumber           bool           `json:"need_phone_number,omitempty"`
+	NeedEmail                 bool           `json:"need_email,omitempty"`
+	NeedShippingAddress       bool           `json:"need_shipping_address,omitempty"`
+	SendPhoneNumberToProvider bool           `json:"send_phone_number_to_provider,omitempty"`
+	SendEmailToProvider       bool           `json:"send_email_to_provider,omitempty"`
+	IsFlexible                bool           `json:"is_flexible,omitempty"`
+	DisableNotification       bool           `json:"disable_notification,omitempty"`
+	ReplyToMessageID          int            `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup               *Button        `json:"reply_markup,omitempty"`
+}
+
+func (obj *Action) sendInvoice(data PayloadSendInvoice) (*SendMessageReturn, error) {
+	if len(data.Prices) == 0 {
+		return nil, fmt.Errorf("sendInvoice: no prices")
+	}
+	ret := new(SendMessageReturn)
+	err := obj.call("sendInvoice", data, ret)
+	return ret, err
+}
+
+// answerShippingQuery sends options, or the reason delivery is impossible
+// when errMsg is set.
+func (obj *Action) answerShippingQuery(query *ShippingQuery, options []ShippingOption, errMsg string) error {
+	type Payload struct {
+		ShippingQueryID string           `json:"shipping_query_id"`
+		Ok              bool             `json:"ok"`
+		ShippingOptions []ShippingOption `json:"shipping_options,omitempty"`
+		ErrorMessage    string           `json:"error_message,omitempty"`
+	}
+	data := Payload{ShippingQueryID: query.ID, Ok: errMsg == ""}
+	if data.Ok {
+		data.ShippingOptions = options
+	} else {
+		data.ErrorMessage = errMsg
+	}
+	return obj.call("answerShippingQuery", data, new(InlineReturn))
+}
+
+// answerPreCheckoutQuery confirms the order, or refuses it with errMsg.
+// Telegram waits at most 10 seconds for this answer.
+func (obj *Action) answerPreCheckoutQuery(query *PreCheckoutQuery, errMsg string) error {
+	type Payload struct {
+		PreCheckoutQueryID string `json:"pre_checkout_query_id"`
+		Ok                 bool   `json:"ok"`
+		ErrorMessage       string `json:"error_message,omitempty"`
+	}
+	data := Payload{PreCheckoutQueryID: query.ID, Ok: errMsg == "", ErrorMessage: errMsg}
+	return obj.call("answerPreCheckoutQuery", data, new(InlineReturn))
+}
+
+// ShippingHandler returns the options for a shipping address, an error is
+// shown to the user as the reason delivery is impossible.
+type ShippingHandler func(obj *Action, query *ShippingQuery) ([]ShippingOption, error)
+
+// PreCheckoutHandler validates an order before it is charged, an error
+// refuses it and is shown to the user.
+type PreCheckoutHandler func(obj *Action, query *PreCheckoutQuery) error
+
+// AnswerShipping adapts h to a ShippingQueryHandler that sends its answer.
+func AnswerShipping(h ShippingHandler) ShippingQueryHandler {
+	return func(obj *Action, query *ShippingQuery) {
+		options, err := h(obj, query)
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		} else if len(options) == 0 {
+			errMsg = "No shipping options for this address"
+		}
+		Dbg(obj.answerShippingQuery(query, options, errMsg))
+	}
+}
+
+// AnswerPreCheckout adapts h to a PreCheckoutQueryHandler that sends its
+// answer. Approved checkouts are recorded for the payment ledger.
+func AnswerPreCheckout(h PreCheckoutHandler) PreCheckoutQueryHandler {
+	return func(obj *Action, query *PreCheckoutQuery) {
+		errMsg := ""
+		if err := h(obj, query); err != nil {
+			errMsg = err.Error()
+		}
+		err := obj.answerPreCheckoutQuery(query, errMsg)
+		Dbg(err)
+		if err == nil && errMsg == "" {
+			Dbg(obj.recordPreCheckout(query))
+		}
+	}
+}
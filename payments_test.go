@@ -0,0 +1,76 @@
+// payments_test.go
+package main
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   string
+		want     int
+		wantErr  bool
+	}{
+		{"EUR", "12.34", 1234, false},
+		{"EUR", "12", 1200, false},
+		{"EUR", "0.05", 5, false},
+		{"EUR", "-0.05", -5, false},
+		{"EUR", "-12.3", -1230, false},
+		{"EUR", ".5", 50, false},
+		{"EUR", "1.", 100, false},
+		{"eur", "1.5", 150, false},
+		{"JPY", "1500", 1500, false},
+		{"jpy", "-7", -7, false},
+		{"JPY", "1.", 1, false},
+		{"JPY", "1.5", 0, true},
+		{"EUR", "1.234", 0, true},
+		{"EUR", "", 0, true},
+		{"EUR", ".", 0, true},
+		{"EUR", "-", 0, true},
+		{"EUR", "1.2.3", 0, true},
+		{"EUR", "1.-5", 0, true},
+		{"EUR", "1,50", 0, true},
+		{"EUR", "abc", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.currency, tt.amount)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAmount(%s, %q) = %d, %v, want %d, error %v", tt.currency, tt.amount, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		currency string
+		minor    int
+		want     string
+	}{
+		{"EUR", 1234, "12.34"},
+		{"EUR", 5, "0.05"},
+		{"EUR", 50, "0.50"},
+		{"EUR", 0, "0.00"},
+		{"EUR", -5, "-0.05"},
+		{"EUR", -1230, "-12.30"},
+		{"JPY", 1500, "1500"},
+		{"JPY", 0, "0"},
+		{"JPY", -7, "-7"},
+	}
+	for _, tt := range tests {
+		got := FormatAmount(tt.currency, tt.minor)
+		if got != tt.want {
+			t.Errorf("FormatAmount(%s, %d) = %q, want %q", tt.currency, tt.minor, got, tt.want)
+		}
+		back, err := ParseAmount(tt.currency, got)
+		if err != nil || back != tt.minor {
+			t.Errorf("ParseAmount(%s, %q) = %d, %v, want %d", tt.currency, got, back, err, tt.minor)
+		}
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	for currency, want := range map[string]int{"EUR": 2, "usd": 2, "JPY": 0, "krw": 0, "CLP": 0} {
+		if got := MinorUnits(currency); got != want {
+			t.Errorf("MinorUnits(%s) = %d, want %d", currency, got, want)
+		}
+	}
+}
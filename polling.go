@@ -0,0 +1,84 @@
+// polling.go
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+	. "github.com/ulvham/helper"
+)
+
+const (
+	stateBucket  = "State"
+	offsetKey    = "offset"
+	pollTimeout  = 30
+	pollLimit    = 100
+	pollRetryGap = 3 * time.Second
+)
+
+// loadOffset restores the last committed update offset from bolt.
+func (obj *Action) loadOffset() int {
+	offset := 0
+	obj.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(stateBucket))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get([]byte(offsetKey)); v != nil {
+			offset, _ = strconv.Atoi(string(v))
+		}
+		return nil
+	})
+	return offset
+}
+
+// commitOffset stores the offset that confirms every update below it.
+func (obj *Action) commitOffset(offset int) error {
+	obj.Offset = offset
+	return obj.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(stateBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(offsetKey), []byte(strconv.Itoa(offset)))
+	})
+}
+
+// process dispatches the batch currently held in obj.Upd. The offset is
+// committed after every update, a crash mid-batch replays only the update
+// that was being handled.
+func (obj *Action) process() {
+	for i := range obj.Upd.Result {
+		upd := &obj.Upd.Result[i]
+		obj.mu.Lock()
+		obj.dispatch(upd)
+		err := obj.commitOffset(upd.UpdateID + 1)
+		obj.mu.Unlock()
+		Dbg(err)
+	}
+}
+
+// poll long-polls getUpdates until stop is closed. After each update is
+// dispatched its update_id+1 is committed to bolt, so a restart neither
+// replays nor skips updates.
+func (obj *Action) poll(stop <-chan struct{}) {
+	obj.Offset = obj.loadOffset()
+	if obj.Timeout == 0 {
+		obj.Timeout = pollTimeout
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if err := obj.getUpdates(); err != nil {
+			Dbg(err)
+			time.Sleep(pollRetryGap)
+			continue
+		}
+		obj.process()
+	}
+}
@@ -0,0 +1,83 @@
+// polling_test.go
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// newTestAction returns an Action on a fresh bolt file.
+func newTestAction(t *testing.T) *Action {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	obj := new(Action)
+	obj.Bolt = db
+	return obj
+}
+
+func messageUpdates(ids ...int) *UpdateReturn {
+	ret := &UpdateReturn{Ok: true}
+	for _, id := range ids {
+		ret.Result = append(ret.Result, Update{UpdateID: id, Message: &Message{MessageID: id, Text: "x"}})
+	}
+	return ret
+}
+
+func TestProcessCommitsEveryUpdate(t *testing.T) {
+	obj := newTestAction(t)
+	var stored []int
+	obj.Handlers.OnMessage = func(obj *Action, msg *Message) {
+		stored = append(stored, obj.loadOffset())
+	}
+	obj.Upd = messageUpdates(10, 11, 12)
+	obj.process()
+
+	// each handler sees the offset confirming every update before its own
+	if want := []int{0, 11, 12}; !reflect.DeepEqual(stored, want) {
+		t.Errorf("offsets seen by handlers = %v, want %v", stored, want)
+	}
+	if got := obj.loadOffset(); got != 13 {
+		t.Errorf("stored offset = %d, want 13", got)
+	}
+	if obj.Offset != 13 {
+		t.Errorf("polling offset = %d, want 13", obj.Offset)
+	}
+}
+
+func TestProcessCrashKeepsHandledUpdates(t *testing.T) {
+	obj := newTestAction(t)
+	obj.Handlers.OnMessage = func(obj *Action, msg *Message) {
+		if msg.MessageID == 21 {
+			panic("crash")
+		}
+	}
+	obj.Upd = messageUpdates(20, 21, 22)
+	func() {
+		defer func() { recover() }()
+		obj.process()
+	}()
+
+	// a restart fetches from 21 on: 20 is not replayed, 21 and 22 not skipped
+	if got := obj.loadOffset(); got != 21 {
+		t.Errorf("stored offset = %d, want 21", got)
+	}
+}
+
+func TestProcessSkipsEmptyUpdates(t *testing.T) {
+	obj := newTestAction(t)
+	calls := 0
+	obj.Handlers.OnMessage = func(obj *Action, msg *Message) { calls++ }
+	// an update that did not decode is kept with its update_id only
+	obj.Upd = &UpdateReturn{Result: []Update{{UpdateID: 30}}}
+	obj.process()
+	if calls != 0 || obj.loadOffset() != 31 {
+		t.Errorf("%d calls, stored offset %d, want 0 and 31", calls, obj.loadOffset())
+	}
+}
@@ -0,0 +1,198 @@
+// split.go
+package main
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the limit of a message's visible text in UTF-16 units.
+const maxMessageLength = 4096
+
+// splitPoints returns the UTF-16 offsets where text has to be cut so no part
+// is longer than limit. It prefers paragraph, then line, then word breaks,
+// the break stays at the end of the earlier part.
+func splitPoints(text string, limit int) []int {
+	runes := []rune(text)
+	pos := make([]int, len(runes)+1)
+	for i, r := range runes {
+		pos[i+1] = pos[i] + utf16.RuneLen(r)
+	}
+	var cuts []int
+	start := 0
+	for pos[len(runes)]-pos[start] > limit {
+		end := start
+		for end < len(runes) && pos[end+1]-pos[start] <= limit {
+			end++
+		}
+		cut := end
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			if i := strings.LastIndex(string(runes[start:end]), sep); i > 0 {
+				cut = start + utf8.RuneCountInString(string(runes[start:end])[:i+len(sep)])
+				break
+			}
+		}
+		if cut <= start {
+			cut = end
+		}
+		cuts = append(cuts, pos[cut])
+		start = cut
+	}
+	return cuts
+}
+
+// splitEntities cuts plain text with its entities, an entity crossing a cut
+// is continued in the next part.
+func splitEntities(text string, ents []MessageEntity, limit int) ([]string, [][]MessageEntity) {
+	encoded := utf16.Encode([]rune(text))
+	bounds := append(append([]int{0}, splitPoints(text, limit)...), len(encoded))
+	var texts []string
+	var chunkEnts [][]MessageEntity
+	for i := 0; i+1 < len(bounds); i++ {
+		from, to := bounds[i], bounds[i+1]
+		texts = append(texts, utf16Slice(encoded, from, to-from))
+		var part []MessageEntity
+		for _, ent := range ents {
+			a, b := ent.Offset, ent.Offset+ent.Length
+			if a < from {
+				a = from
+			}
+			if b > to {
+				b = to
+			}
+			if a < b {
+				ent.Offset, ent.Length = a-from, b-a
+				part = append(part, ent)
+			}
+		}
+		chunkEnts = append(chunkEnts, part)
+	}
+	return texts, chunkEnts
+}
+
+type htmlUnit struct {
+	raw   string
+	vis   string
+	tag   string
+	close bool
+}
+
+// htmlUnits tokenizes Telegram HTML into tags and visible characters, an
+// escape such as &amp; is one character.
+func htmlUnits(s string) []htmlUnit {
+	var units []htmlUnit
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			if j := strings.IndexByte(s[i:], '>'); j > 0 {
+				raw := s[i : i+j+1]
+				name := strings.TrimPrefix(strings.Trim(raw, "<>"), "/")
+				if k := strings.IndexAny(name, " \t\n"); k >= 0 {
+					name = name[:k]
+				}
+				units = append(units, htmlUnit{raw: raw, tag: strings.ToLower(name), close: raw[1] == '/'})
+				i += j + 1
+				continue
+			}
+		}
+		if s[i] == '&' {
+			if j := strings.IndexByte(s[i:], ';'); j > 1 && j < 10 {
+				raw := s[i : i+j+1]
+				units = append(units, htmlUnit{raw: raw, vis: html.UnescapeString(raw)})
+				i += j + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		units = append(units, htmlUnit{raw: s[i : i+size], vis: s[i : i+size]})
+		i += size
+	}
+	return units
+}
+
+// splitHTML cuts HTML by its visible text, tags open at a cut are closed at
+// the end of the part and opened again at the start of the next one.
+func splitHTML(s string, limit int) []string {
+	units := htmlUnits(s)
+	var visible strings.Builder
+	for _, u := range units {
+		visible.WriteString(u.vis)
+	}
+	cuts := splitPoints(visible.String(), limit)
+
+	var parts []string
+	var open []htmlUnit
+	var b strings.Builder
+	pos := 0
+	for _, u := range units {
+		// closing tags right at a cut stay in the earlier part
+		if !u.close && len(cuts) > 0 && pos >= cuts[0] {
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i].tag + ">")
+			}
+			parts = append(parts, b.String())
+			b.Reset()
+			for _, o := range open {
+				b.WriteString(o.raw)
+			}
+			cuts = cuts[1:]
+		}
+		b.WriteString(u.raw)
+		switch {
+		case u.tag != "" && !u.close:
+			open = append(open, u)
+		case u.tag != "" && u.close:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].tag == u.tag {
+					open = append(open[:i], open[i+1:]...)
+					break
+				}
+			}
+		}
+		pos += utf16Len(u.vis)
+	}
+	return append(parts, b.String())
+}
+
+// sendLongMessage sends data split into parts Telegram accepts, in order.
+// Only the last part carries the reply markup and only the first replies to
+// ReplyToMessageID. It returns the message IDs of the parts sent so far.
+func (obj *Action) sendLongMessage(data PayloadMesageSend) ([]int, error) {
+	var texts []string
+	var ents [][]MessageEntity
+	switch data.ParseMode {
+	case ParseModeHTML:
+		texts = splitHTML(data.Text, maxMessageLength)
+	case ParseModeEntities:
+		texts, ents = splitEntities(data.Text, data.Entities, maxMessageLength)
+	default:
+		if utf16Len(data.Text) > maxMessageLength {
+			return nil, errors.New("sendLongMessage: Markdown cannot be split, render it as HTML or entities")
+		}
+		texts = []string{data.Text}
+	}
+
+	var ids []int
+	for i, text := range texts {
+		part := data
+		part.Text = text
+		if ents != nil {
+			part.Entities = ents[i]
+		}
+		if i > 0 {
+			part.ReplyToMessageID = 0
+		}
+		if i < len(texts)-1 {
+			part.ReplyMarkup = nil
+		}
+		ret := new(SendMessageReturn)
+		if err := obj.call("sendMessage", part, ret); err != nil {
+			return ids, err
+		}
+		obj.Msg = ret
+		ids = append(ids, ret.Result.MessageID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,73 @@
+// split_test.go
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		limit int
+		want  []string
+	}{
+		{"fits", "<b>x</b>", 10, []string{"<b>x</b>"}},
+		{"word break reopens tag", "<b>hello world</b>", 6, []string{"<b>hello </b>", "<b>world</b>"}},
+		{"nested tags and emoji", "<b><i>😀😀 ab</i></b>", 4, []string{"<b><i>😀😀</i></b>", "<b><i> ab</i></b>"}},
+		{"closing tag at cut stays", "<b>ab</b>cd", 2, []string{"<b>ab</b>", "cd"}},
+		{"escape is one character", "a &amp; b", 2, []string{"a ", "&amp; ", "b"}},
+		{"attributes are kept", `<a href="x">abcd</a>`, 2, []string{`<a href="x">ab</a>`, `<a href="x">cd</a>`}},
+	}
+	for _, tt := range tests {
+		if got := splitHTML(tt.html, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: splitHTML(%q, %d) = %q, want %q", tt.name, tt.html, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSplitEntities(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		ents      []MessageEntity
+		limit     int
+		wantTexts []string
+		wantEnts  [][]MessageEntity
+	}{
+		{
+			"fits",
+			"hi", []MessageEntity{{Type: "bold", Offset: 0, Length: 2}}, 10,
+			[]string{"hi"},
+			[][]MessageEntity{{{Type: "bold", Offset: 0, Length: 2}}},
+		},
+		{
+			"entity continues",
+			"hello world", []MessageEntity{{Type: "bold", Offset: 0, Length: 11}}, 6,
+			[]string{"hello ", "world"},
+			[][]MessageEntity{{{Type: "bold", Offset: 0, Length: 6}}, {{Type: "bold", Offset: 0, Length: 5}}},
+		},
+		{
+			"emoji counts two units",
+			"😀 ab 😀", []MessageEntity{{Type: "italic", Offset: 3, Length: 5}}, 4,
+			[]string{"😀 ", "ab ", "😀"},
+			[][]MessageEntity{nil, {{Type: "italic", Offset: 0, Length: 3}}, {{Type: "italic", Offset: 0, Length: 2}}},
+		},
+		{
+			"entity in later part only",
+			"aaa bbb", []MessageEntity{{Type: "code", Offset: 4, Length: 3}}, 4,
+			[]string{"aaa ", "bbb"},
+			[][]MessageEntity{nil, {{Type: "code", Offset: 0, Length: 3}}},
+		},
+	}
+	for _, tt := range tests {
+		texts, ents := splitEntities(tt.text, tt.ents, tt.limit)
+		if !reflect.DeepEqual(texts, tt.wantTexts) {
+			t.Errorf("%s: texts = %q, want %q", tt.name, texts, tt.wantTexts)
+		}
+		if !reflect.DeepEqual(ents, tt.wantEnts) {
+			t.Errorf("%s: entities = %+v, want %+v", tt.name, ents, tt.wantEnts)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// webhook.go
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+	// maxWebhookBody is far above any update Telegram sends.
+	maxWebhookBody = 1 << 20
+)
+
+type PayloadSetWebhook struct {
+	Url                string   `json:"url"`
+	MaxConnections     int      `json:"max_connections,omitempty"`
+	AllowedUpdates     []string `json:"allowed_updates,omitempty"`
+	DropPendingUpdates bool     `json:"drop_pending_updates,omitempty"`
+	SecretToken        string   `json:"secret_token,omitempty"`
+}
+
+type PayloadDeleteWebhook struct {
+	DropPendingUpdates bool `json:"drop_pending_updates,omitempty"`
+}
+
+type WebhookInfoReturn struct {
+	Result struct {
+		Url                  string   `json:"url"`
+		HasCustomCertificate bool     `json:"has_custom_certificate"`
+		PendingUpdateCount   int      `json:"pending_update_count"`
+		LastErrorDate        int      `json:"last_error_date"`
+		LastErrorMessage     string   `json:"last_error_message"`
+		MaxConnections       int      `json:"max_connections"`
+		AllowedUpdates       []string `json:"allowed_updates"`
+	} `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Ok          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// setWebhook registers url with Telegram. The secret token of the Action is
+// sent along so that ServeHTTP can tell Telegram's requests from anyone else's.
+// With certFile set the public certificate is uploaded, Telegram only trusts
+// a self-signed endpoint that way.
+func (obj *Action) setWebhook(url, certFile string) error {
+	data := PayloadSetWebhook{}
+	data.Url = url
+	data.SecretToken = obj.SecretToken
+	data.AllowedUpdates = obj.allowedUpdates()
+	if certFile == "" {
+		return obj.call("setWebhook", data, nil)
+	}
+	params := map[string]string{"url": data.Url}
+	if data.SecretToken != "" {
+		params["secret_token"] = data.SecretToken
+	}
+	if len(data.AllowedUpdates) > 0 {
+		allowed, err := json.Marshal(data.AllowedUpdates)
+		if err != nil {
+			return err
+		}
+		params["allowed_updates"] = string(allowed)
+	}
+	return obj.callUpload("setWebhook", params, map[string]InputFile{"certificate": FilePath(certFile)}, nil)
+}
+
+func (obj *Action) deleteWebhook() error {
+	return obj.call("deleteWebhook", PayloadDeleteWebhook{}, nil)
+}
+
+func (obj *Action) getWebhookInfo() (*WebhookInfoReturn, error) {
+	ret := new(WebhookInfoReturn)
+	err := obj.call("getWebhookInfo", struct{}{}, ret)
+	return ret, err
+}
+
+// ServeHTTP receives one update per POST from Telegram and feeds it to the
+// same handlers the polling path uses. net/http serves requests concurrently,
+// handlers run one at a time under obj.mu.
+func (obj *Action) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if obj.SecretToken != "" {
+		got := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(obj.SecretToken)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	upd := Update{}
+	body := http.MaxBytesReader(w, r.Body, maxWebhookBody)
+	if err := json.NewDecoder(body).Decode(&upd); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	obj.mu.Lock()
+	obj.dispatch(&upd)
+	obj.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// serveWebhook listens on addr and serves updates at path. With certFile and
+// keyFile set it serves HTTPS itself, otherwise plain HTTP for use behind a
+// TLS-terminating reverse proxy.
+func (obj *Action) serveWebhook(addr, path, certFile, keyFile string) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, obj)
+	if certFile != "" && keyFile != "" {
+		return http.ListenAndServeTLS(addr, certFile, keyFile, mux)
+	}
+	return http.ListenAndServe(addr, mux)
+}
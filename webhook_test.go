@@ -0,0 +1,75 @@
+// webhook_test.go
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeHTTP(t *testing.T) {
+	var got []string
+	obj := new(Action)
+	obj.SecretToken = "s3cret"
+	obj.Handlers.OnMessage = func(obj *Action, msg *Message) {
+		got = append(got, msg.Text)
+	}
+	srv := httptest.NewServer(obj)
+	defer srv.Close()
+
+	update := `{"update_id":1,"message":{"message_id":5,"text":"hi","chat":{"id":9}}}`
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		body     string
+		status   int
+		dispatch bool
+	}{
+		{"update", "POST", "s3cret", update, http.StatusOK, true},
+		{"wrong method", "GET", "s3cret", "", http.StatusMethodNotAllowed, false},
+		{"no token", "POST", "", update, http.StatusUnauthorized, false},
+		{"wrong token", "POST", "s3cre", update, http.StatusUnauthorized, false},
+		{"bad body", "POST", "s3cret", `{"update_id":`, http.StatusBadRequest, false},
+		{"body too large", "POST", "s3cret", `{"update_id":1,"message":{"text":"` + strings.Repeat("x", maxWebhookBody) + `"}}`, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		got = nil
+		req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.token != "" {
+			req.Header.Set(secretTokenHeader, tt.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+		if dispatched := len(got) == 1 && got[0] == "hi"; dispatched != tt.dispatch {
+			t.Errorf("%s: handler got %q", tt.name, got)
+		}
+	}
+}
+
+func TestServeHTTPWithoutSecret(t *testing.T) {
+	obj := new(Action)
+	calls := 0
+	obj.Handlers.OnMessage = func(obj *Action, msg *Message) { calls++ }
+	srv := httptest.NewServer(obj)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"update_id":1,"message":{"text":"hi"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 1 {
+		t.Errorf("status %d, %d calls, want 200 and 1", resp.StatusCode, calls)
+	}
+}